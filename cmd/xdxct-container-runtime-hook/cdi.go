@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/inject"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/modifier"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+// doCDIPrestart injects the requested devices into the container when the hook is invoked
+// directly (e.g. through the docker --gpus flag) on a host that is not configured for the legacy
+// mode. The CDI edits for the requested devices are computed in process and applied to the
+// created container.
+func doCDIPrestart(mode string, container containerConfig) {
+	logger := logger.New()
+
+	cfg, err := getToolkitConfig()
+	if err != nil {
+		log.Panicln("error getting toolkit config:", err)
+	}
+
+	spec, err := oci.NewFileSpec(oci.GetSpecFilePath(container.Bundle)).Load()
+	if err != nil {
+		log.Panicln("could not load OCI spec:", err)
+	}
+
+	var m oci.SpecModifier
+	switch mode {
+	case "cdi":
+		m, err = modifier.NewCDIModifier(logger, cfg, oci.NewMemorySpec(spec))
+	case "jit-cdi":
+		m, err = modifier.NewJitCDIModifier(logger, cfg, oci.NewMemorySpec(spec))
+	default:
+		log.Panicf("unsupported mode %q", mode)
+	}
+	if err != nil {
+		log.Panicln("failed to construct modifier:", err)
+	}
+	if m == nil {
+		return
+	}
+
+	// The modifier is applied to an empty spec so that only the required edits are captured.
+	edits := &specs.Spec{}
+	if err := m.Modify(edits); err != nil {
+		log.Panicln("failed to determine container edits:", err)
+	}
+
+	rootfs := container.Rootfs
+	if !filepath.IsAbs(rootfs) {
+		rootfs = filepath.Join(container.Bundle, rootfs)
+	}
+
+	if err := inject.New(logger, container.Pid, rootfs).Inject(edits, container.State); err != nil {
+		log.Panicln("failed to inject devices:", err)
+	}
+}
+
+// getToolkitConfig loads the toolkit config from the file specified on the command line or the default location.
+func getToolkitConfig() (*config.Config, error) {
+	if len(*configflag) == 0 {
+		return config.GetConfig()
+	}
+	cfg, err := config.New(config.WithConfigFile(*configflag))
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Config()
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/gpus"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/mod/semver"
 )
@@ -41,6 +43,9 @@ type containerConfig struct {
 	Rootfs string
 	Image  image.GPU
 	Xdxct  *xdxctConfig
+	Bundle string
+	// State is the raw container state that was passed to the hook
+	State []byte
 }
 
 // Root from OCI runtime spec
@@ -161,23 +166,29 @@ func isPrivileged(s *Spec) bool {
 	return image.IsPrivileged(&fullSpec)
 }
 
-func getDevicesFromEnvvar(image image.GPU, swarmResourceEnvvars []string) *string {
+func getDevicesFromEnvvar(gpuImage image.GPU, swarmResourceEnvvars []string, lister image.DeviceLister) *string {
 	// We check if the image has at least one of the Swarm resource envvars defined and use this
 	// if specified.
 	var hasSwarmEnvvar bool
 	for _, envvar := range swarmResourceEnvvars {
-		if image.HasEnvvar(envvar) {
+		if gpuImage.HasEnvvar(envvar) {
 			hasSwarmEnvvar = true
 			break
 		}
 	}
 
-	var devices []string
+	var requested image.VisibleDevices
 	if hasSwarmEnvvar {
-		devices = image.DevicesFromEnvvars(swarmResourceEnvvars...).List()
+		requested = gpuImage.DevicesFromEnvvars(swarmResourceEnvvars...)
 	} else {
-		devices = image.DevicesFromEnvvars(envXDXVisibleDevices).List()
+		requested = gpuImage.DevicesFromEnvvars(envXDXVisibleDevices)
+	}
+
+	resolved, err := image.ResolveVisibleDevices(requested, lister)
+	if err != nil {
+		log.Panicln("failed to resolve requested devices:", err)
 	}
+	devices := resolved.List()
 
 	if len(devices) == 0 {
 		return nil
@@ -225,7 +236,7 @@ func getDevicesFromMounts(mounts []Mount) *string {
 	return &ret
 }
 
-func getDevices(hookConfig *HookConfig, image image.GPU, mounts []Mount, privileged bool) *string {
+func getDevices(hookConfig *HookConfig, image image.GPU, mounts []Mount, privileged bool, lister image.DeviceLister) *string {
 	// If enabled, try and get the device list from volume mounts first
 	if hookConfig.AcceptDeviceListAsVolumeMounts {
 		devices := getDevicesFromMounts(mounts)
@@ -235,7 +246,7 @@ func getDevices(hookConfig *HookConfig, image image.GPU, mounts []Mount, privile
 	}
 
 	// Fallback to reading from the environment variable if privileges are correct
-	devices := getDevicesFromEnvvar(image, hookConfig.getSwarmResourceEnvvars())
+	devices := getDevicesFromEnvvar(image, hookConfig.getSwarmResourceEnvvars(), lister)
 	if devices == nil {
 		return nil
 	}
@@ -276,11 +287,11 @@ func (c *HookConfig) getDriverCapabilities(gpuImage image.GPU, legacyImage bool)
 	return capabilities
 }
 
-func getXdxctConfig(hookConfig *HookConfig, image image.GPU, mounts []Mount, privileged bool) *xdxctConfig {
+func getXdxctConfig(hookConfig *HookConfig, image image.GPU, mounts []Mount, privileged bool, lister image.DeviceLister) *xdxctConfig {
 	legacyImage := image.IsLegacy()
 
 	var devices string
-	if d := getDevices(hookConfig, image, mounts, privileged); d != nil {
+	if d := getDevices(hookConfig, image, mounts, privileged, lister); d != nil {
 		devices = *d
 	} else {
 		// 'nil' devices means this is not a GPU container.
@@ -303,8 +314,11 @@ func getXdxctConfig(hookConfig *HookConfig, image image.GPU, mounts []Mount, pri
 
 func getContainerConfig(hook HookConfig) (config containerConfig) {
 	var h HookState
-	d := json.NewDecoder(os.Stdin)
-	if err := d.Decode(&h); err != nil {
+	state, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Panicln("could not read container state:", err)
+	}
+	if err := json.Unmarshal(state, &h); err != nil {
 		log.Panicln("could not decode container state:", err)
 	}
 
@@ -328,6 +342,8 @@ func getContainerConfig(hook HookConfig) (config containerConfig) {
 		Pid:    h.Pid,
 		Rootfs: s.Root.Path,
 		Image:  image,
-		Xdxct:  getXdxctConfig(&hook, image, s.Mounts, privileged),
+		Xdxct:  getXdxctConfig(&hook, image, s.Mounts, privileged, gpus.NewDeviceLister(nil)),
+		Bundle: b,
+		State:  state,
 	}
 }
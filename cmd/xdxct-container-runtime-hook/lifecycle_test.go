@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+)
+
+type staticLister []image.Device
+
+func (l staticLister) ListDevices() ([]image.Device, error) {
+	return l, nil
+}
+
+func TestRemoveLdsoconfdFiles(t *testing.T) {
+	rootfs := t.TempDir()
+	confd := filepath.Join(rootfs, "etc/ld.so.conf.d")
+	require.NoError(t, os.MkdirAll(confd, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(confd, "nvcr-123.conf"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confd, "libc.conf"), nil, 0644))
+
+	require.NoError(t, removeLdsoconfdFiles(rootfs))
+
+	entries, err := os.ReadDir(confd)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "libc.conf", entries[0].Name())
+
+	require.NoError(t, removeLdsoconfdFiles(t.TempDir()))
+}
+
+func TestRemoveLdsoconfdFilesDoesNotFollowSymlinks(t *testing.T) {
+	host := t.TempDir()
+	hostConfd := filepath.Join(host, "ld.so.conf.d")
+	require.NoError(t, os.MkdirAll(hostConfd, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostConfd, "nvcr-123.conf"), nil, 0644))
+
+	rootfs := t.TempDir()
+	require.NoError(t, os.Symlink(host, filepath.Join(rootfs, "etc")))
+
+	require.Error(t, removeLdsoconfdFiles(rootfs))
+	require.FileExists(t, filepath.Join(hostConfd, "nvcr-123.conf"))
+}
+
+func TestGetDeviceNodes(t *testing.T) {
+	devRoot := t.TempDir()
+	byPath := filepath.Join(devRoot, "dev/dri/by-path")
+	require.NoError(t, os.MkdirAll(byPath, 0755))
+	for link, target := range map[string]string{
+		"pci-0000:01:00.0-card":   "../card0",
+		"pci-0000:01:00.0-render": "../renderD128",
+		"pci-0000:02:00.0-card":   "../card1",
+		"pci-0000:02:00.0-render": "../renderD129",
+	} {
+		require.NoError(t, os.Symlink(target, filepath.Join(byPath, link)))
+	}
+
+	lister := staticLister{
+		{Index: 0, UUID: "GPU-0", PCIBusID: "0000:01:00.0"},
+		{Index: 1, UUID: "GPU-1", PCIBusID: "0000:02:00.0"},
+	}
+
+	testCases := []struct {
+		description   string
+		requested     []string
+		expectedNodes []string
+	}{
+		{
+			description:   "all devices",
+			requested:     []string{"all"},
+			expectedNodes: []string{"/dev/dri/card0", "/dev/dri/renderD128", "/dev/dri/card1", "/dev/dri/renderD129"},
+		},
+		{
+			description:   "device by index",
+			requested:     []string{"1"},
+			expectedNodes: []string{"/dev/dri/card1", "/dev/dri/renderD129"},
+		},
+		{
+			description:   "device by UUID",
+			requested:     []string{"GPU-0"},
+			expectedNodes: []string{"/dev/dri/card0", "/dev/dri/renderD128"},
+		},
+		{
+			description: "unknown device",
+			requested:   []string{"GPU-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			nodes, err := getDeviceNodes(lister, devRoot, tc.requested)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedNodes, nodes)
+		})
+	}
+}
+
+func TestGetMissingDeviceNodes(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "dev/dri"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "dev/dri/card0"), nil, 0644))
+
+	missing := getMissingDeviceNodes(root, []string{"/dev/dri/card0", "/dev/dri/renderD128"})
+	require.Equal(t, []string{"/dev/dri/card0", "/dev/dri/renderD128"}, missing)
+
+	// Existing device nodes are not reported as missing.
+	if _, err := os.Stat("/dev/null"); err != nil {
+		t.Skip("/dev/null is not available")
+	}
+	missing = getMissingDeviceNodes("/", []string{"/dev/null", "/dev/dri/xdxct-missing"})
+	require.Equal(t, []string{"/dev/dri/xdxct-missing"}, missing)
+}
@@ -79,9 +79,6 @@ func doPrestart() {
 	cli := hook.XdxctContainerCLI
 
 	container := getContainerConfig(*hook)
-	if !hook.XDXCTContainerRuntimeHook.SkipModeDetection && info.ResolveAutoMode(&logInterceptor{}, hook.XDXCTContainerRuntime.Mode, container.Image) != "legacy" {
-		log.Panicln("invoking the XDXCT Container Runtime Hook directly (e.g. specifying the docker --gpus flag) is not supported. Please use the XDXCT Container Runtime (e.g. specify the --runtime=xdxct flag) instead.")
-	}
 
 	xdxct := container.Xdxct
 	if xdxct == nil {
@@ -89,6 +86,13 @@ func doPrestart() {
 		return
 	}
 
+	if !hook.XDXCTContainerRuntimeHook.SkipModeDetection {
+		if mode := info.ResolveAutoMode(&logInterceptor{}, hook.XDXCTContainerRuntime.Mode, container.Image); mode != "legacy" {
+			doCDIPrestart(mode, container)
+			return
+		}
+	}
+
 	rootfs := getRootfsPath(container)
 
 	args := []string{getCLIPath(cli)}
@@ -146,8 +150,10 @@ func usage() {
 	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, "\nCommands:\n")
 	fmt.Fprintf(os.Stderr, "  prestart\n        run the prestart hook\n")
-	fmt.Fprintf(os.Stderr, "  poststart\n        no-op\n")
-	fmt.Fprintf(os.Stderr, "  poststop\n        no-op\n")
+	fmt.Fprintf(os.Stderr, "  createRuntime\n        run the hook at the createRuntime lifecycle (same as prestart)\n")
+	fmt.Fprintf(os.Stderr, "  createContainer\n        run the hook at the createContainer lifecycle (same as prestart)\n")
+	fmt.Fprintf(os.Stderr, "  poststart\n        verify that the requested devices are visible in the container (if enabled)\n")
+	fmt.Fprintf(os.Stderr, "  poststop\n        clean up the files created in the container root filesystem\n")
 }
 
 func main() {
@@ -166,12 +172,14 @@ func main() {
 	}
 
 	switch args[0] {
-	case "prestart":
+	case "prestart", "createRuntime", "createContainer":
 		doPrestart()
 		os.Exit(0)
 	case "poststart":
-		fallthrough
+		doPoststart()
+		os.Exit(0)
 	case "poststop":
+		doPoststop()
 		os.Exit(0)
 	default:
 		flag.Usage()
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/gpus"
+)
+
+// doPoststart optionally verifies that the device nodes of the requested devices are visible in
+// the mount namespace of the started container. Missing devices are logged as warnings.
+func doPoststart() {
+	defer exit()
+	log.SetFlags(0)
+
+	hook, err := getHookConfig()
+	if err != nil || hook == nil {
+		log.Panicln("error getting hook config:", err)
+	}
+	if !hook.XDXCTContainerRuntimeHook.VerifyDevices {
+		return
+	}
+
+	container := getContainerConfig(*hook)
+	if container.Xdxct == nil || container.Xdxct.Devices == "" || container.Pid == 0 {
+		return
+	}
+
+	nodes, err := getDeviceNodes(gpus.NewDeviceLister(nil), "/", strings.Split(container.Xdxct.Devices, ","))
+	if err != nil {
+		log.Printf("WARNING: failed to get device nodes for requested devices: %v", err)
+		return
+	}
+
+	root := filepath.Join("/proc", strconv.Itoa(container.Pid), "root")
+	for _, missing := range getMissingDeviceNodes(root, nodes) {
+		log.Printf("WARNING: device node %v is not visible in container", missing)
+	}
+}
+
+// getDeviceNodes returns the DRM device nodes of the requested devices. Devices are
+// selected by index, UUID, or PCI bus ID, or all devices are selected with "all".
+func getDeviceNodes(lister image.DeviceLister, devRoot string, requested []string) ([]string, error) {
+	available, err := lister.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range requested {
+		selected[id] = true
+	}
+
+	var nodes []string
+	for _, d := range available {
+		if !selected["all"] && !selected[strconv.Itoa(d.Index)] && !selected[d.UUID] && !selected[d.PCIBusID] {
+			continue
+		}
+		for _, kind := range []string{"card", "render"} {
+			link := filepath.Join(devRoot, "dev/dri/by-path", fmt.Sprintf("pci-%s-%s", d.PCIBusID, kind))
+			target, err := os.Readlink(link)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %v: %v", link, err)
+			}
+			nodes = append(nodes, filepath.Join("/dev/dri", filepath.Base(target)))
+		}
+	}
+	return nodes, nil
+}
+
+// getMissingDeviceNodes returns the device nodes that do not exist below the specified root.
+func getMissingDeviceNodes(root string, nodes []string) []string {
+	var missing []string
+	for _, node := range nodes {
+		info, err := os.Lstat(filepath.Join(root, node))
+		if err != nil || info.Mode()&os.ModeDevice == 0 {
+			missing = append(missing, node)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	// ldsoconfdPattern matches the config files created by the update-ldcache hook.
+	ldsoconfdPattern = "nvcr-*.conf"
+)
+
+// doPoststop cleans up the per-container artefacts created by the toolkit hooks. Since these
+// are created in the container root filesystem, they would otherwise be left behind in
+// root filesystems that persist beyond the lifetime of the container.
+func doPoststop() {
+	defer exit()
+	log.SetFlags(0)
+
+	var h HookState
+	state, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Panicln("could not read container state:", err)
+	}
+	if err := json.Unmarshal(state, &h); err != nil {
+		log.Panicln("could not decode container state:", err)
+	}
+
+	b := h.Bundle
+	if len(b) == 0 {
+		b = h.BundlePath
+	}
+	s := loadSpec(path.Join(b, "config.json"))
+
+	rootfs := s.Root.Path
+	if !filepath.IsAbs(rootfs) {
+		rootfs = filepath.Join(b, rootfs)
+	}
+
+	if err := removeLdsoconfdFiles(rootfs); err != nil {
+		log.Printf("WARNING: failed to remove ld.so.conf.d files: %v", err)
+	}
+}
+
+// removeLdsoconfdFiles removes the /etc/ld.so.conf.d files created by the update-ldcache hook
+// from the specified root filesystem. Symlinks are not followed to ensure that no files outside
+// of the root filesystem are removed.
+func removeLdsoconfdFiles(rootfs string) error {
+	dir := rootfs
+	for _, p := range []string{"etc", "ld.so.conf.d"} {
+		dir = filepath.Join(dir, p)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%v is not a directory", dir)
+		}
+	}
+
+	configs, err := filepath.Glob(filepath.Join(dir, ldsoconfdPattern))
+	if err != nil {
+		return err
+	}
+	for _, config := range configs {
+		info, err := os.Lstat(config)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := os.Remove(config); err != nil {
+			return fmt.Errorf("failed to remove %v: %v", config, err)
+		}
+	}
+	return nil
+}
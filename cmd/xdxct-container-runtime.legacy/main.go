@@ -13,6 +13,6 @@ func main() {
 
 	err := rt.Run(os.Args)
 	if err != nil {
-		os.Exit(1)
+		os.Exit(runtime.ExitCode(err))
 	}
 }
@@ -10,6 +10,6 @@ func main() {
 	r := runtime.New()
 	err := r.Run(os.Args)
 	if err != nil {
-		os.Exit(1)
+		os.Exit(runtime.ExitCode(err))
 	}
 }
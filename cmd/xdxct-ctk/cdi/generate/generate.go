@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	cdi "tags.cncf.io/container-device-interface/pkg/parser"
@@ -36,6 +37,10 @@ type options struct {
 	class              string
 
 	librarySearchPaths cli.StringSlice
+	devSymlinks        bool
+
+	hookTimeouts    cli.StringSlice
+	hookTimeoutsMap config.HookTimeouts
 
 	csv struct {
 		files          cli.StringSlice
@@ -102,6 +107,11 @@ func (m command) build() *cli.Command {
 			Usage:       "Specify the path to search for libraries when discovering the entities that should be included in the CDI specification.\n\tNote: This option only applies to CSV mode.",
 			Destination: &opts.librarySearchPaths,
 		},
+		&cli.BoolFlag{
+			Name:        "create-dev-symlinks",
+			Usage:       "Create the development (.so) symlinks for the driver libraries in addition to the SONAME symlinks.",
+			Destination: &opts.devSymlinks,
+		},
 		&cli.StringFlag{
 			Name:        "xdxct-ctk-path",
 			Usage:       "Specify the path to use for the xdxct-ctk in the generated CDI specification. If this is left empty, the path will be searched.",
@@ -121,6 +131,11 @@ func (m command) build() *cli.Command {
 			Value:       "gpu",
 			Destination: &opts.class,
 		},
+		&cli.StringSliceFlag{
+			Name:        "hook-timeout",
+			Usage:       "Specify the timeout in seconds for an xdxct-ctk hook in the generated CDI specification as <hook>=<seconds>. A hook name of 'default' applies to all other hooks. If no timeouts are specified, the timeouts from the config file are used.",
+			Destination: &opts.hookTimeouts,
+		},
 	}
 
 	return &c
@@ -157,6 +172,12 @@ func (m command) validateFlags(c *cli.Context, opts *options) error {
 		}
 	}
 
+	hookTimeouts, err := m.getHookTimeouts(opts.hookTimeouts.Value())
+	if err != nil {
+		return err
+	}
+	opts.hookTimeoutsMap = hookTimeouts
+
 	if err := cdi.ValidateVendorName(opts.vendor); err != nil {
 		return fmt.Errorf("invalid CDI vendor name: %v", err)
 	}
@@ -203,6 +224,7 @@ func (m command) generateSpec(opts *options) (spec.Interface, error) {
 		xdxcdi.WithDevRoot(opts.devRoot),
 		xdxcdi.WithXDXCTCTKPath(opts.xdxctCTKPath),
 		xdxcdi.WithMode(opts.mode),
+		xdxcdi.WithDevSymlinks(opts.devSymlinks),
 		// To csv mode
 		xdxcdi.WithLibrarySearchPaths(opts.librarySearchPaths.Value()),
 		xdxcdi.WithCSVFiles(opts.csv.files.Value()),
@@ -233,5 +255,36 @@ func (m command) generateSpec(opts *options) (spec.Interface, error) {
 			transform.WithSkipIfExists(true),
 		),
 		spec.WithPermissions(0644),
+		spec.WithHookTimeouts(opts.hookTimeoutsMap),
 	)
 }
+
+// getHookTimeouts returns the hook timeouts specified as <hook>=<seconds>. If none are specified,
+// the timeouts from the config file are returned.
+func (m command) getHookTimeouts(values []string) (config.HookTimeouts, error) {
+	if len(values) == 0 {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			m.logger.Warningf("Failed to load config; using default hook timeouts: %v", err)
+			cfg, err = config.GetDefault()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return cfg.XDXCTCTKConfig.HookTimeouts, nil
+	}
+
+	timeouts := make(config.HookTimeouts)
+	for _, v := range values {
+		name, seconds, found := strings.Cut(v, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid hook timeout %q: expected <hook>=<seconds>", v)
+		}
+		timeout, err := strconv.Atoi(seconds)
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid hook timeout %q: expected a non-negative number of seconds", v)
+		}
+		timeouts[name] = timeout
+	}
+	return timeouts, nil
+}
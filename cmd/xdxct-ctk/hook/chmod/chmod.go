@@ -1,16 +1,17 @@
 package chmod
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
-	"syscall"
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
-	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/sys/unix"
 )
 
 type command struct {
@@ -38,7 +39,7 @@ func (m command) build() *cli.Command {
 	// Create the 'chmod' command
 	c := cli.Command{
 		Name:  "chmod",
-		Usage: "Set the permissions of folders in the container by running chmod. The container root is prefixed to the specified paths.",
+		Usage: "Set the permissions of folders in the container. The specified paths are resolved relative to the container root.",
 		Before: func(c *cli.Context) error {
 			return validateFlags(c, &cfg)
 		},
@@ -55,7 +56,7 @@ func (m command) build() *cli.Command {
 		},
 		&cli.StringFlag{
 			Name:        "mode",
-			Usage:       "Specify the file mode",
+			Usage:       "Specify the file mode in octal",
 			Destination: &cfg.mode,
 		},
 		&cli.StringFlag{
@@ -72,6 +73,9 @@ func validateFlags(c *cli.Context, cfg *config) error {
 	if strings.TrimSpace(cfg.mode) == "" {
 		return fmt.Errorf("a non-empty mode must be specified")
 	}
+	if _, err := parseMode(cfg.mode); err != nil {
+		return err
+	}
 
 	for _, p := range cfg.paths.Value() {
 		if strings.TrimSpace(p) == "" {
@@ -87,6 +91,7 @@ func (m command) run(c *cli.Context, cfg *config) error {
 	if err != nil {
 		return fmt.Errorf("failed to load container state: %v", err)
 	}
+	logger.AddFields(m.logger, s.LogFields())
 
 	containerRoot, err := s.GetContainerRoot()
 	if err != nil {
@@ -101,26 +106,51 @@ func (m command) run(c *cli.Context, cfg *config) error {
 		m.logger.Debugf("No paths specified; exiting")
 		return nil
 	}
+	defer func() {
+		for _, p := range paths {
+			p.Close()
+		}
+	}()
 
-	locator := lookup.NewExecutableLocator(m.logger, "")
-	targets, err := locator.Locate("chmod")
-	if err != nil {
-		return fmt.Errorf("failed to locate chmod: %v", err)
+	mode, _ := parseMode(cfg.mode)
+
+	var errs error
+	for _, p := range paths {
+		m.logger.Debugf("Setting mode of %v to %#o", p.Name(), mode)
+		// The mode is applied through the opened file descriptor so that the path is not resolved again.
+		if err := unix.Fchmodat(unix.AT_FDCWD, procPath(p), mode, 0); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to set mode of %v: %v", p.Name(), err))
+		}
 	}
-	chmodPath := targets[0]
+	return errs
+}
 
-	args := append([]string{filepath.Base(chmodPath), cfg.mode}, paths...)
+// parseMode parses the specified mode as an octal number.
+func parseMode(mode string) (uint32, error) {
+	m, err := strconv.ParseUint(strings.TrimSpace(mode), 8, 32)
+	if err != nil || m > 07777 {
+		return 0, fmt.Errorf("invalid mode %q: only octal modes are supported", mode)
+	}
+	return uint32(m), nil
+}
 
-	return syscall.Exec(chmodPath, args, nil)
+// procPath returns the path through which the specified opened file can be referenced.
+func procPath(f *os.File) string {
+	return fmt.Sprintf("/proc/self/fd/%d", f.Fd())
 }
 
-// getPaths updates the specified paths relative to the root.
-func (m command) getPaths(root string, paths []string) []string {
-	var pathsInRoot []string
+// getPaths opens the specified paths relative to the root. Paths that resolve to locations
+// outside of the root are skipped.
+func (m command) getPaths(root string, paths []string) []*os.File {
+	var pathsInRoot []*os.File
 	for _, f := range paths {
-		path := filepath.Join(root, f)
-		if _, err := os.Stat(path); err != nil {
-			m.logger.Debugf("Skipping path %q: %v", path, err)
+		path, err := securejoin.OpenInRoot(root, f, unix.O_PATH)
+		if errors.Is(err, securejoin.ErrEscape) {
+			m.logger.Warningf("Refusing to change mode of %q outside of container root: %v", f, err)
+			continue
+		}
+		if err != nil {
+			m.logger.Debugf("Skipping path %q: %v", f, err)
 			continue
 		}
 		pathsInRoot = append(pathsInRoot, path)
@@ -0,0 +1,250 @@
+package chown
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// initialUIDMap is the content of /proc/self/uid_map in the initial user namespace.
+	initialUIDMap = "0 0 4294967295"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type config struct {
+	paths         cli.StringSlice
+	owner         string
+	group         string
+	containerSpec string
+}
+
+// NewCommand constructs a chown command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build the chown command
+func (m command) build() *cli.Command {
+	cfg := config{}
+
+	// Create the 'chown' command
+	c := cli.Command{
+		Name:  "chown",
+		Usage: "Set the owner and group of paths in the container. The specified paths are resolved relative to the container root.",
+		Before: func(c *cli.Context) error {
+			return validateFlags(c, &cfg)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &cfg)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "path",
+			Usage:       "Specifiy a path to apply the specified owner and group to",
+			Destination: &cfg.paths,
+		},
+		&cli.StringFlag{
+			Name:        "owner",
+			Usage:       "Specify the owner as a user name or numeric ID. User names are resolved in the container's /etc/passwd",
+			Destination: &cfg.owner,
+		},
+		&cli.StringFlag{
+			Name:        "group",
+			Usage:       "Specify the group as a group name or numeric ID. Group names are resolved in the container's /etc/group",
+			Destination: &cfg.group,
+		},
+		&cli.StringFlag{
+			Name:        "container-spec",
+			Usage:       "Specify the path to the OCI container spec. If empty or '-' the spec will be read from STDIN",
+			Destination: &cfg.containerSpec,
+		},
+	}
+
+	return &c
+}
+
+func validateFlags(c *cli.Context, cfg *config) error {
+	if strings.TrimSpace(cfg.owner) == "" && strings.TrimSpace(cfg.group) == "" {
+		return fmt.Errorf("an owner or group must be specified")
+	}
+
+	for _, p := range cfg.paths.Value() {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("paths must not be empty")
+		}
+	}
+
+	return nil
+}
+
+func (m command) run(c *cli.Context, cfg *config) error {
+	s, err := oci.LoadContainerState(cfg.containerSpec)
+	if err != nil {
+		return fmt.Errorf("failed to load container state: %v", err)
+	}
+	logger.AddFields(m.logger, s.LogFields())
+
+	spec, err := s.LoadSpec()
+	if err != nil {
+		return fmt.Errorf("failed to load container spec: %v", err)
+	}
+	containerRoot, err := s.GetContainerRoot()
+	if err != nil {
+		return fmt.Errorf("failed to determined container root: %v", err)
+	}
+	if containerRoot == "" {
+		return fmt.Errorf("empty container root detected")
+	}
+
+	uid, err := lookupID(containerRoot, "/etc/passwd", cfg.owner)
+	if err != nil {
+		return fmt.Errorf("failed to resolve owner: %v", err)
+	}
+	gid, err := lookupID(containerRoot, "/etc/group", cfg.group)
+	if err != nil {
+		return fmt.Errorf("failed to resolve group: %v", err)
+	}
+
+	// If the hook is not run in the user namespace of the container, the IDs in the container
+	// are mapped to the IDs on the host.
+	if spec.Linux != nil && isInitialUserNamespace() {
+		uid, err = mapID(uid, spec.Linux.UIDMappings)
+		if err != nil {
+			return fmt.Errorf("failed to map owner: %v", err)
+		}
+		gid, err = mapID(gid, spec.Linux.GIDMappings)
+		if err != nil {
+			return fmt.Errorf("failed to map group: %v", err)
+		}
+	}
+
+	paths := m.getPaths(containerRoot, cfg.paths.Value())
+	if len(paths) == 0 {
+		m.logger.Debugf("No paths specified; exiting")
+		return nil
+	}
+	defer func() {
+		for _, p := range paths {
+			p.Close()
+		}
+	}()
+
+	var errs error
+	for _, p := range paths {
+		m.logger.Debugf("Setting owner of %v to %d:%d", p.Name(), uid, gid)
+		// The owner is applied through the opened file descriptor so that the path is not resolved again.
+		if err := unix.Fchownat(unix.AT_FDCWD, fmt.Sprintf("/proc/self/fd/%d", p.Fd()), uid, gid, 0); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to set owner of %v: %v", p.Name(), err))
+		}
+	}
+	return errs
+}
+
+// getPaths opens the specified paths relative to the root. Paths that resolve to locations
+// outside of the root are skipped.
+func (m command) getPaths(root string, paths []string) []*os.File {
+	var pathsInRoot []*os.File
+	for _, f := range paths {
+		path, err := securejoin.OpenInRoot(root, f, unix.O_PATH)
+		if errors.Is(err, securejoin.ErrEscape) {
+			m.logger.Warningf("Refusing to change owner of %q outside of container root: %v", f, err)
+			continue
+		}
+		if err != nil {
+			m.logger.Debugf("Skipping path %q: %v", f, err)
+			continue
+		}
+		pathsInRoot = append(pathsInRoot, path)
+	}
+
+	return pathsInRoot
+}
+
+// lookupID returns the numeric ID for the specified name. Numeric IDs are returned as is and
+// names are resolved in the specified database file (/etc/passwd or /etc/group) of the
+// container. An empty name returns -1 so that the ID is left unchanged.
+func lookupID(root string, database string, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1, nil
+	}
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return int(id), nil
+	}
+
+	f, err := securejoin.OpenInRoot(root, database, os.O_RDONLY)
+	if err != nil {
+		return -1, fmt.Errorf("failed to open %v: %w", database, err)
+	}
+	defer f.Close()
+
+	id, err := findID(f, name)
+	if err != nil {
+		return -1, fmt.Errorf("failed to resolve %q in %v: %v", name, database, err)
+	}
+	return id, nil
+}
+
+// findID returns the ID for the named entry in the content of an /etc/passwd or /etc/group file.
+// In both cases the name is the first and the ID the third field of an entry.
+func findID(r io.Reader, name string) (int, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || fields[0] != name {
+			continue
+		}
+		id, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return -1, fmt.Errorf("invalid ID %q", fields[2])
+		}
+		return int(id), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return -1, err
+	}
+	return -1, fmt.Errorf("not found")
+}
+
+// mapID maps the specified ID in the container to the ID on the host using the specified
+// mappings. If no mappings are specified, the ID is returned as is.
+func mapID(id int, mappings []specs.LinuxIDMapping) (int, error) {
+	if id < 0 || len(mappings) == 0 {
+		return id, nil
+	}
+	for _, m := range mappings {
+		if uint32(id) >= m.ContainerID && uint32(id)-m.ContainerID < m.Size {
+			return int(m.HostID + uint32(id) - m.ContainerID), nil
+		}
+	}
+	return -1, fmt.Errorf("ID %d is not mapped in the container", id)
+}
+
+// isInitialUserNamespace checks whether the current process is running in the initial user namespace.
+func isInitialUserNamespace() bool {
+	contents, err := os.ReadFile("/proc/self/uid_map")
+	if err != nil {
+		return true
+	}
+	return strings.Join(strings.Fields(string(contents)), " ") == initialUIDMap
+}
@@ -0,0 +1,90 @@
+package chown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupID(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc/group"), []byte("root:x:0:\nvideo:x:44:\nrender:x:109:user\ninvalid:x:abc:\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc/passwd"), []byte("root:x:0:0:root:/root:/bin/sh\n"), 0644))
+
+	testCases := []struct {
+		description   string
+		database      string
+		name          string
+		expected      int
+		expectedError bool
+	}{
+		{description: "empty name", database: "/etc/group", name: "", expected: -1},
+		{description: "numeric ID", database: "/etc/group", name: "1000", expected: 1000},
+		{description: "group name", database: "/etc/group", name: "render", expected: 109},
+		{description: "user name", database: "/etc/passwd", name: "root", expected: 0},
+		{description: "unknown name", database: "/etc/group", name: "xdxct", expectedError: true},
+		{description: "invalid ID", database: "/etc/group", name: "invalid", expectedError: true},
+		{description: "missing database", database: "/etc/shadow", name: "root", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			id, err := lookupID(root, tc.database, tc.name)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, id)
+		})
+	}
+}
+
+func TestLookupIDOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "rootfs")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "etc/group"), []byte("video:x:44:\n"), 0644))
+	require.NoError(t, os.MkdirAll(root, 0755))
+	require.NoError(t, os.Symlink("../etc", filepath.Join(root, "etc")))
+
+	_, err := lookupID(root, "/etc/group", "video")
+	require.Error(t, err)
+}
+
+func TestMapID(t *testing.T) {
+	mappings := []specs.LinuxIDMapping{
+		{ContainerID: 0, HostID: 100000, Size: 1000},
+		{ContainerID: 1000, HostID: 1000, Size: 1},
+	}
+
+	testCases := []struct {
+		description   string
+		id            int
+		mappings      []specs.LinuxIDMapping
+		expected      int
+		expectedError bool
+	}{
+		{description: "no mappings", id: 44, expected: 44},
+		{description: "unchanged", id: -1, mappings: mappings, expected: -1},
+		{description: "first mapping", id: 44, mappings: mappings, expected: 100044},
+		{description: "second mapping", id: 1000, mappings: mappings, expected: 1000},
+		{description: "unmapped", id: 1001, mappings: mappings, expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			id, err := mapID(tc.id, tc.mappings)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, id)
+		})
+	}
+}
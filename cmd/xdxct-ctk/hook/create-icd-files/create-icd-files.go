@@ -0,0 +1,190 @@
+package icd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// vulkanAPIVersion is the Vulkan version reported in generated ICD files. The loader
+	// queries the version supported by the driver when this is loaded.
+	vulkanAPIVersion = "1.3.0"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type config struct {
+	vulkanLibrary string
+	eglLibrary    string
+	openclLibrary string
+	containerSpec string
+}
+
+// icdFile describes an ICD file that is created in the container.
+type icdFile struct {
+	dir      string
+	name     string
+	contents func(library string) ([]byte, error)
+}
+
+var (
+	vulkanICD = icdFile{
+		dir:  "/usr/share/vulkan/icd.d",
+		name: "xdxgpu_icd.json",
+		contents: func(library string) ([]byte, error) {
+			return marshalICD(map[string]string{"library_path": library, "api_version": vulkanAPIVersion})
+		},
+	}
+	eglICD = icdFile{
+		dir:  "/usr/share/glvnd/egl_vendor.d",
+		name: "50_xdxgpu.json",
+		contents: func(library string) ([]byte, error) {
+			return marshalICD(map[string]string{"library_path": library})
+		},
+	}
+	openclICD = icdFile{
+		dir:  "/etc/OpenCL/vendors",
+		name: "xdxgpu.icd",
+		contents: func(library string) ([]byte, error) {
+			return []byte(library + "\n"), nil
+		},
+	}
+)
+
+// NewCommand constructs a create-icd-files command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build the create-icd-files command
+func (m command) build() *cli.Command {
+	cfg := config{}
+
+	// Create the 'create-icd-files' command
+	c := cli.Command{
+		Name:  "create-icd-files",
+		Usage: "Create the Vulkan, EGL and OpenCL ICD files for the injected driver libraries. Existing files in the container are not replaced.",
+		Action: func(c *cli.Context) error {
+			return m.run(c, &cfg)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "vulkan-library",
+			Usage:       "Specify the container path of the Vulkan driver library",
+			Destination: &cfg.vulkanLibrary,
+		},
+		&cli.StringFlag{
+			Name:        "egl-library",
+			Usage:       "Specify the container path of the EGL vendor library",
+			Destination: &cfg.eglLibrary,
+		},
+		&cli.StringFlag{
+			Name:        "opencl-library",
+			Usage:       "Specify the container path of the OpenCL driver library",
+			Destination: &cfg.openclLibrary,
+		},
+		&cli.StringFlag{
+			Name:        "container-spec",
+			Usage:       "Specify the path to the OCI container spec. If empty or '-' the spec will be read from STDIN",
+			Destination: &cfg.containerSpec,
+		},
+	}
+
+	return &c
+}
+
+func (m command) run(c *cli.Context, cfg *config) error {
+	s, err := oci.LoadContainerState(cfg.containerSpec)
+	if err != nil {
+		return fmt.Errorf("failed to load container state: %v", err)
+	}
+	logger.AddFields(m.logger, s.LogFields())
+
+	containerRoot, err := s.GetContainerRoot()
+	if err != nil {
+		return fmt.Errorf("failed to determined container root: %v", err)
+	}
+	if containerRoot == "" {
+		return fmt.Errorf("empty container root detected")
+	}
+
+	requested := []struct {
+		icd     icdFile
+		library string
+	}{
+		{vulkanICD, cfg.vulkanLibrary},
+		{eglICD, cfg.eglLibrary},
+		{openclICD, cfg.openclLibrary},
+	}
+
+	var errs error
+	for _, r := range requested {
+		if r.library == "" {
+			continue
+		}
+		if err := m.createICDFile(containerRoot, r.icd, r.library); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to create %v: %v", r.icd.name, err))
+		}
+	}
+	return errs
+}
+
+// createICDFile creates the ICD file for the specified library in the container unless the
+// file already exists.
+func (m command) createICDFile(root string, icd icdFile, library string) error {
+	dir, err := securejoin.MkdirAllInRoot(root, icd.dir, 0755)
+	if errors.Is(err, securejoin.ErrEscape) {
+		m.logger.Warningf("Refusing to create ICD file outside of container root: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	var stat unix.Stat_t
+	if err := unix.Fstatat(int(dir.Fd()), icd.name, &stat, unix.AT_SYMLINK_NOFOLLOW); err == nil {
+		m.logger.Debugf("Skipping existing ICD file %v/%v", icd.dir, icd.name)
+		return nil
+	}
+
+	contents, err := icd.contents(library)
+	if err != nil {
+		return err
+	}
+	m.logger.Debugf("Creating ICD file %v/%v for %v", icd.dir, icd.name, library)
+	return securejoin.ReplaceFile(dir, icd.name, func(w io.Writer) error {
+		_, err := w.Write(contents)
+		return err
+	})
+}
+
+// marshalICD returns the contents of a JSON ICD file with the specified ICD properties.
+func marshalICD(properties map[string]string) ([]byte, error) {
+	contents, err := json.MarshalIndent(
+		map[string]interface{}{
+			"file_format_version": "1.0.0",
+			"ICD":                 properties,
+		},
+		"", "    ",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return append(contents, '\n'), nil
+}
@@ -0,0 +1,74 @@
+package icd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateICDFile(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	m := command{logger: logger}
+
+	testCases := []struct {
+		description string
+		icd         icdFile
+		existing    string
+		expected    string
+	}{
+		{
+			description: "vulkan",
+			icd:         vulkanICD,
+			expected: `{
+    "ICD": {
+        "api_version": "1.3.0",
+        "library_path": "/usr/lib/xdxgpu/libvlk_xdxgpu.so"
+    },
+    "file_format_version": "1.0.0"
+}
+`,
+		},
+		{
+			description: "egl",
+			icd:         eglICD,
+			expected: `{
+    "ICD": {
+        "library_path": "/usr/lib/xdxgpu/libvlk_xdxgpu.so"
+    },
+    "file_format_version": "1.0.0"
+}
+`,
+		},
+		{
+			description: "opencl",
+			icd:         openclICD,
+			expected:    "/usr/lib/xdxgpu/libvlk_xdxgpu.so\n",
+		},
+		{
+			description: "existing file is not replaced",
+			icd:         openclICD,
+			existing:    "/usr/lib/libOpenCL_other.so\n",
+			expected:    "/usr/lib/libOpenCL_other.so\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, tc.icd.dir, tc.icd.name)
+			if tc.existing != "" {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte(tc.existing), 0644))
+			}
+
+			require.NoError(t, m.createICDFile(root, tc.icd, "/usr/lib/xdxgpu/libvlk_xdxgpu.so"))
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(contents))
+		})
+	}
+}
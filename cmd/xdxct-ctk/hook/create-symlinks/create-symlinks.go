@@ -1,15 +1,19 @@
 package symlinks
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/XDXCT/xdxct-container-toolkit/internal/discover/csv"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup/symlinks"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/sys/unix"
 )
 
 type command struct {
@@ -38,7 +42,7 @@ func (m command) build() *cli.Command {
 	// Create the '' command
 	c := cli.Command{
 		Name:  "create-symlinks",
-		Usage: "A hook to create symlinks in the container. This can be used to proces CSV mount specs",
+		Usage: "A hook to create symlinks in the container. This can be used to process CSV mount specs",
 		Action: func(c *cli.Context) error {
 			return m.run(c, &cfg)
 		},
@@ -75,6 +79,7 @@ func (m command) run(c *cli.Context, cfg *config) error {
 	if err != nil {
 		return fmt.Errorf("failed to load container state: %v", err)
 	}
+	logger.AddFields(m.logger, s.LogFields())
 
 	containerRoot, err := s.GetContainerRoot()
 	if err != nil {
@@ -82,6 +87,24 @@ func (m command) run(c *cli.Context, cfg *config) error {
 	}
 
 	var candidates []string
+	for _, filename := range cfg.filenames.Value() {
+		mountSpecs, err := csv.ParseFile(filename)
+		if err != nil {
+			m.logger.Warningf("Skipping CSV file %v: %v", filename, err)
+			continue
+		}
+		for _, ms := range mountSpecs {
+			if ms.Type != csv.MountSpecSym {
+				continue
+			}
+			chain, err := symlinks.ResolveChain(cfg.hostRoot, ms.Path)
+			if err != nil {
+				m.logger.Warningf("Skipping symlink %v: %v", ms.Path, err)
+				continue
+			}
+			candidates = append(candidates, chain...)
+		}
+	}
 
 	created := make(map[string]bool)
 	// candidates is a list of absolute paths to symlinks in a chain, or the final target of the chain.
@@ -95,7 +118,13 @@ func (m command) run(c *cli.Context, cfg *config) error {
 			continue
 		}
 
-		err = m.createLink(created, cfg.hostRoot, containerRoot, target, candidate)
+		// The candidates are located on the host and are created at the same path in the container.
+		link, err := changeRoot(cfg.hostRoot, "/", candidate)
+		if err != nil {
+			m.logger.Warningf("Failed to resolve path for link %v relative to %v: %v", candidate, cfg.hostRoot, err)
+			continue
+		}
+		err = m.createLink(created, containerRoot, target, link)
 		if err != nil {
 			m.logger.Warningf("Failed to create link %v: %v", []string{target, candidate}, err)
 		}
@@ -109,7 +138,7 @@ func (m command) run(c *cli.Context, cfg *config) error {
 			continue
 		}
 
-		err := m.createLink(created, cfg.hostRoot, containerRoot, parts[0], parts[1])
+		err := m.createLink(created, containerRoot, parts[0], parts[1])
 		if err != nil {
 			m.logger.Warningf("Failed to create link %v: %v", parts, err)
 		}
@@ -119,34 +148,59 @@ func (m command) run(c *cli.Context, cfg *config) error {
 
 }
 
-func (m command) createLink(created map[string]bool, hostRoot string, containerRoot string, target string, link string) error {
-	linkPath, err := changeRoot(hostRoot, containerRoot, link)
-	if err != nil {
-		m.logger.Warningf("Failed to resolve path for link %v relative to %v: %v", link, containerRoot, err)
-	}
+// createLink creates the specified link to target in the container. Links that were already
+// created are skipped. An existing link to a different target, for example one shipped in the
+// container image, is kept and a warning with both targets is logged.
+func (m command) createLink(created map[string]bool, containerRoot string, target string, link string) error {
+	linkPath := filepath.Clean(link)
 	if created[linkPath] {
 		m.logger.Debugf("Link %v already created", linkPath)
 		return nil
 	}
 
-	targetPath, err := changeRoot(hostRoot, "/", target)
-	if err != nil {
-		m.logger.Warningf("Failed to resolve path for target %v relative to %v: %v", target, "/", err)
+	m.logger.Infof("Symlinking %v to %v", filepath.Join(containerRoot, linkPath), target)
+	dir, err := securejoin.MkdirAllInRoot(containerRoot, filepath.Dir(linkPath), 0755)
+	if errors.Is(err, securejoin.ErrEscape) {
+		m.logger.Warningf("Refusing to create link %v outside of container root: %v", linkPath, err)
+		return nil
 	}
-
-	m.logger.Infof("Symlinking %v to %v", linkPath, targetPath)
-	err = os.MkdirAll(filepath.Dir(linkPath), 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create directory: %v", err)
 	}
-	err = os.Symlink(target, linkPath)
+	defer dir.Close()
+
+	err = unix.Symlinkat(target, int(dir.Fd()), filepath.Base(linkPath))
+	if err == unix.EEXIST {
+		existing, readErr := readLinkTarget(dir, filepath.Base(linkPath))
+		if readErr != nil {
+			return fmt.Errorf("failed to create symlink: %v", err)
+		}
+		if existing == target {
+			m.logger.Debugf("Link %v already exists", linkPath)
+		} else {
+			m.logger.Warningf("Keeping existing link %v to %v instead of %v", linkPath, existing, target)
+		}
+		created[linkPath] = true
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create symlink: %v", err)
 	}
+	created[linkPath] = true
 
 	return nil
 }
 
+// readLinkTarget returns the target of the symlink with the specified name in the directory.
+func readLinkTarget(dir *os.File, name string) (string, error) {
+	buf := make([]byte, unix.PathMax)
+	n, err := unix.Readlinkat(int(dir.Fd()), name, buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
 func changeRoot(current string, new string, path string) (string, error) {
 	if !filepath.IsAbs(path) {
 		return path, nil
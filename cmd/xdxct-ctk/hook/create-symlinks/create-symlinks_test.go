@@ -0,0 +1,81 @@
+package symlinks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateLink(t *testing.T) {
+	testCases := []struct {
+		description    string
+		existingTarget string
+		existingFile   bool
+		expectedTarget string
+		expectedError  bool
+		expectWarning  bool
+	}{
+		{
+			description:    "link is created",
+			expectedTarget: "libxdxgpu-ml.so.1.2.3",
+		},
+		{
+			description:    "existing link to the same target",
+			existingTarget: "libxdxgpu-ml.so.1.2.3",
+			expectedTarget: "libxdxgpu-ml.so.1.2.3",
+		},
+		{
+			description:    "existing link to a different target is kept",
+			existingTarget: "libxdxgpu-ml.so.1.0.0",
+			expectedTarget: "libxdxgpu-ml.so.1.0.0",
+			expectWarning:  true,
+		},
+		{
+			description:   "existing file",
+			existingFile:  true,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			logger, hook := testlog.NewNullLogger()
+			containerRoot := t.TempDir()
+			linkPath := filepath.Join(containerRoot, "usr/lib/libxdxgpu-ml.so.1")
+			require.NoError(t, os.MkdirAll(filepath.Dir(linkPath), 0755))
+			if tc.existingTarget != "" {
+				require.NoError(t, os.Symlink(tc.existingTarget, linkPath))
+			}
+			if tc.existingFile {
+				require.NoError(t, os.WriteFile(linkPath, nil, 0644))
+			}
+
+			created := make(map[string]bool)
+			err := command{logger: logger}.createLink(created, containerRoot, "libxdxgpu-ml.so.1.2.3", "/usr/lib/libxdxgpu-ml.so.1")
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, created["/usr/lib/libxdxgpu-ml.so.1"])
+
+			target, err := os.Readlink(linkPath)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedTarget, target)
+
+			var warned bool
+			for _, e := range hook.AllEntries() {
+				if e.Level == logrus.WarnLevel {
+					warned = true
+					require.Contains(t, e.Message, "libxdxgpu-ml.so.1.0.0")
+					require.Contains(t, e.Message, "libxdxgpu-ml.so.1.2.3")
+				}
+			}
+			require.Equal(t, tc.expectWarning, warned)
+		})
+	}
+}
@@ -1,11 +1,17 @@
 package hook
 
 import (
+	"time"
+
 	chmod "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook/chmod"
-	
+	chown "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook/chown"
+	icd "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook/create-icd-files"
 	ldcache "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook/update-ldcache"
 	symlinks "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook/create-symlinks"
+	manifest "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook/write-manifest"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,13 +33,70 @@ func (m hookCommand) build() *cli.Command {
 	hook := cli.Command{
 		Name:  "hook",
 		Usage: "A collection of hooks that may be injected into an OCI spec",
+		Before: func(c *cli.Context) error {
+			m.setupLogging()
+			return nil
+		},
 	}
 
 	hook.Subcommands = []*cli.Command{
 		ldcache.NewCommand(m.logger),
 		symlinks.NewCommand(m.logger),
 		chmod.NewCommand(m.logger),
+		chown.NewCommand(m.logger),
+		manifest.NewCommand(m.logger),
+		icd.NewCommand(m.logger),
+	}
+	for _, c := range hook.Subcommands {
+		m.reportRun(c)
 	}
 
 	return &hook
 }
+
+// reportRun wraps the action of the specified hook so that its duration and outcome are logged.
+// Since a failing hook is generally only reported as an opaque error by the low-level runtime,
+// this allows failed or slow hooks to be identified from the configured log target.
+func (m hookCommand) reportRun(c *cli.Command) {
+	action := c.Action
+	if action == nil {
+		return
+	}
+	c.Action = func(ctx *cli.Context) error {
+		start := time.Now()
+		err := action(ctx)
+		duration := time.Since(start)
+
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		logger.AddFields(m.logger, logrus.Fields{
+			"hook":     c.Name,
+			"duration": duration.String(),
+			"outcome":  outcome,
+		})
+		if err != nil {
+			m.logger.Errorf("Hook %v failed after %v: %v", c.Name, duration, err)
+			return err
+		}
+		m.logger.Infof("Hook %v completed in %v", c.Name, duration)
+		return nil
+	}
+}
+
+// setupLogging attaches a per-invocation trace ID to the log entries of the hooks and
+// configures the log target. Since the output of hooks is generally not captured by
+// the low-level runtime, the journald or syslog targets allow for this to be inspected.
+func (m hookCommand) setupLogging() {
+	logger.AddFields(m.logger, logrus.Fields{"trace-id": logger.NewTraceID()})
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		m.logger.Warningf("Failed to load config: %v", err)
+		return
+	}
+	if err := logger.SetTarget(m.logger, cfg.XDXCTCTKConfig.LogTarget, "xdxct-ctk"); err != nil {
+		m.logger.Warningf("Failed to set log target: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestReportRun(t *testing.T) {
+	testCases := []struct {
+		description     string
+		actionError     error
+		expectedLevel   string
+		expectedOutcome string
+	}{
+		{
+			description:     "success",
+			expectedLevel:   "info",
+			expectedOutcome: "success",
+		},
+		{
+			description:     "failure",
+			actionError:     errors.New("failed"),
+			expectedLevel:   "error",
+			expectedOutcome: "failure",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&buf)
+			logger.SetFormatter(&logrus.JSONFormatter{})
+
+			c := &cli.Command{
+				Name: "update-ldcache",
+				Action: func(*cli.Context) error {
+					return tc.actionError
+				},
+			}
+			hookCommand{logger: logger}.reportRun(c)
+
+			err := c.Action(cli.NewContext(cli.NewApp(), nil, nil))
+			require.Equal(t, tc.actionError, err)
+
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			require.Equal(t, tc.expectedLevel, entry["level"])
+			require.Equal(t, "update-ldcache", entry["hook"])
+			require.Equal(t, tc.expectedOutcome, entry["outcome"])
+			require.NotEmpty(t, entry["duration"])
+		})
+	}
+}
+
+func TestReportRunWithoutAction(t *testing.T) {
+	c := &cli.Command{Name: "update-ldcache"}
+	hookCommand{logger: logrus.New()}.reportRun(c)
+	require.Nil(t, c.Action)
+}
@@ -0,0 +1,142 @@
+package ldcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+	"golang.org/x/sys/unix"
+)
+
+type libc string
+
+const (
+	libcUnknown = libc("")
+	libcGlibc   = libc("glibc")
+	libcMusl    = libc("musl")
+)
+
+var (
+	// libDirs are the directories in which the dynamic loader of an image is searched for.
+	libDirs = []string{"/lib", "/lib64", "/usr/lib", "/usr/lib64"}
+	// muslDefaultPath is the library path used by musl if no path file is present.
+	muslDefaultPath = []string{"/lib", "/usr/local/lib", "/usr/lib"}
+	// glibcLoaderPatterns match the glibc dynamic loaders in a library directory. This includes
+	// the loaders in multiarch subdirectories and the ld64.so loaders used on ppc64le and s390x.
+	glibcLoaderPatterns = []string{"ld-linux*.so.*", "*/ld-linux*.so.*", "ld64.so.*", "*/ld64.so.*"}
+)
+
+// getLibc returns the C library used by the container by checking for the dynamic loader.
+// For musl, the architecture of the loader is also returned. If no loader is found, glibc
+// is assumed if the container includes an /etc/ld.so.cache.
+func getLibc(root string) (libc, string, error) {
+	var isGlibc bool
+	for _, dir := range libDirs {
+		resolved, err := securejoin.Resolve(root, dir)
+		if err != nil {
+			return libcUnknown, "", err
+		}
+
+		musl, err := filepath.Glob(filepath.Join(resolved, "ld-musl-*.so.1"))
+		if err != nil {
+			return libcUnknown, "", err
+		}
+		if len(musl) > 0 {
+			arch := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(musl[0]), "ld-musl-"), ".so.1")
+			return libcMusl, arch, nil
+		}
+
+		for _, pattern := range glibcLoaderPatterns {
+			glibc, err := filepath.Glob(filepath.Join(resolved, pattern))
+			if err != nil {
+				return libcUnknown, "", err
+			}
+			isGlibc = isGlibc || len(glibc) > 0
+		}
+	}
+
+	if isGlibc || hasLdsoCache(root) {
+		return libcGlibc, "", nil
+	}
+	return libcUnknown, "", nil
+}
+
+// hasLdsoCache checks whether the container includes an /etc/ld.so.cache file.
+func hasLdsoCache(root string) bool {
+	resolved, err := securejoin.Resolve(root, "/etc/ld.so.cache")
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(resolved)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// updateMuslPath adds the specified folders to the /etc/ld-musl-<arch>.path file in the
+// container. If the file does not exist, it is created including musl's default library path.
+func updateMuslPath(root string, arch string, folders []string) (string, error) {
+	etc, err := securejoin.MkdirAllInRoot(root, "/etc", 0755)
+	if err != nil {
+		return "", err
+	}
+	defer etc.Close()
+
+	name := fmt.Sprintf("ld-musl-%v.path", arch)
+	paths, err := readMuslPath(etc, name)
+	if err != nil {
+		return "", err
+	}
+
+	configured := make(map[string]bool)
+	for _, path := range paths {
+		configured[path] = true
+	}
+	for _, folder := range folders {
+		if configured[folder] {
+			continue
+		}
+		paths = append(paths, folder)
+		configured[folder] = true
+	}
+
+	err = securejoin.ReplaceFile(etc, name, func(w io.Writer) error {
+		_, err := io.WriteString(w, strings.Join(paths, "\n")+"\n")
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write path file: %v", err)
+	}
+
+	return filepath.Join(etc.Name(), name), nil
+}
+
+// readMuslPath reads the paths from the specified musl path file in the directory. musl's
+// default library path is returned if the file does not exist.
+func readMuslPath(dir *os.File, name string) ([]string, error) {
+	fd, err := unix.Openat(int(dir.Fd()), name, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if errors.Is(err, unix.ENOENT) {
+		return append([]string{}, muslDefaultPath...), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %v: %v", name, err)
+	}
+	f := os.NewFile(uintptr(fd), filepath.Join(dir.Name(), name))
+	defer f.Close()
+
+	contents, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %v", name, err)
+	}
+
+	// Entries in the path file are separated by newlines or colons.
+	var paths []string
+	for _, path := range strings.FieldsFunc(string(contents), func(r rune) bool { return r == '\n' || r == ':' }) {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,93 @@
+package ldcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLibc(t *testing.T) {
+	testCases := []struct {
+		description  string
+		files        []string
+		expectedLibc libc
+		expectedArch string
+	}{
+		{
+			description: "static image",
+			files:       []string{"/bin/app"},
+		},
+		{
+			description:  "musl",
+			files:        []string{"/lib/ld-musl-aarch64.so.1"},
+			expectedLibc: libcMusl,
+			expectedArch: "aarch64",
+		},
+		{
+			description:  "glibc",
+			files:        []string{"/lib64/ld-linux-x86-64.so.2"},
+			expectedLibc: libcGlibc,
+		},
+		{
+			description:  "multiarch glibc",
+			files:        []string{"/usr/lib/x86_64-linux-gnu/ld-linux-x86-64.so.2"},
+			expectedLibc: libcGlibc,
+		},
+		{
+			description:  "ppc64le glibc",
+			files:        []string{"/lib64/ld64.so.2"},
+			expectedLibc: libcGlibc,
+		},
+		{
+			description:  "s390x glibc",
+			files:        []string{"/lib/ld64.so.1"},
+			expectedLibc: libcGlibc,
+		},
+		{
+			description:  "glibc with ld.so.cache only",
+			files:        []string{"/etc/ld.so.cache"},
+			expectedLibc: libcGlibc,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			root := t.TempDir()
+			for _, f := range tc.files {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(f)), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(root, f), nil, 0755))
+			}
+
+			libc, arch, err := getLibc(root)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedLibc, libc)
+			require.Equal(t, tc.expectedArch, arch)
+		})
+	}
+}
+
+func TestUpdateMuslPath(t *testing.T) {
+	root := t.TempDir()
+
+	pathFile, err := updateMuslPath(root, "x86_64", []string{"/usr/lib/xdxct", "/usr/lib"})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "etc/ld-musl-x86_64.path"), pathFile)
+
+	contents, err := os.ReadFile(pathFile)
+	require.NoError(t, err)
+	require.Equal(t, "/lib\n/usr/local/lib\n/usr/lib\n/usr/lib/xdxct\n", string(contents))
+
+	require.NoError(t, os.WriteFile(pathFile, []byte("/lib:/usr/lib\n"), 0644))
+	_, err = updateMuslPath(root, "x86_64", []string{"/usr/lib/xdxct"})
+	require.NoError(t, err)
+
+	contents, err = os.ReadFile(pathFile)
+	require.NoError(t, err)
+	require.Equal(t, "/lib\n/usr/lib\n/usr/lib/xdxct\n", string(contents))
+
+	info, err := os.Stat(pathFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
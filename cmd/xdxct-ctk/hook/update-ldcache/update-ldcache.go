@@ -1,13 +1,14 @@
 package ldcache
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"syscall"
+	"io"
 
+	"github.com/XDXCT/xdxct-container-toolkit/internal/ldcache"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
 	"github.com/urfave/cli/v2"
 )
 
@@ -35,7 +36,7 @@ func (m command) build() *cli.Command {
 	// Create the 'update-ldcache' command
 	c := cli.Command{
 		Name:  "update-ldcache",
-		Usage: "Update ldcache in a container by running ldconfig",
+		Usage: "Update ldcache in a container. The ld.so.cache is updated directly without running an executable from the container. For musl-based containers the musl library path is updated instead.",
 		Action: func(c *cli.Context) error {
 			return m.run(c, &cfg)
 		},
@@ -62,29 +63,90 @@ func (m command) run(c *cli.Context, cfg *config) error {
 	if err != nil {
 		return fmt.Errorf("failed to load container state: %v", err)
 	}
+	logger.AddFields(m.logger, s.LogFields())
 
 	containerRoot, err := s.GetContainerRoot()
 	if err != nil {
 		return fmt.Errorf("failed to determined container root: %v", err)
 	}
+	if containerRoot == "" {
+		return fmt.Errorf("empty container root detected")
+	}
+	folders := cfg.folders.Value()
+
+	libc, arch, err := getLibc(containerRoot)
+	if errors.Is(err, securejoin.ErrEscape) {
+		m.logger.Warningf("Refusing to update ld cache: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to detect container libc: %v", err)
+	}
 
-	_, err = os.Stat(filepath.Join(containerRoot, "/etc/ld.so.cache"))
-	if err != nil && os.IsNotExist(err) {
-		m.logger.Debugf("No ld.so.cache found, skipping update")
+	switch libc {
+	case libcMusl:
+		if len(folders) == 0 {
+			m.logger.Debugf("No folders to add to musl library path")
+			return nil
+		}
+		pathFile, err := updateMuslPath(containerRoot, arch, folders)
+		if errors.Is(err, securejoin.ErrEscape) {
+			m.logger.Warningf("Refusing to update musl library path outside of container root: %v", err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update musl library path: %v", err)
+		}
+		m.logger.Debugf("Added folders %v to %v", folders, pathFile)
+		return nil
+	case libcUnknown:
+		m.logger.Debugf("No dynamic loader found in container; skipping update")
 		return nil
 	}
 
-	err = m.createConfig(containerRoot, cfg.folders.Value())
+	err = m.createConfig(containerRoot, folders)
 	if err != nil {
 		return fmt.Errorf("failed to update ld.so.conf: %v", err)
 	}
 
-	args := []string{"/sbin/ldconfig"}
-	if containerRoot != "" {
-		args = append(args, "-r", containerRoot)
+	return m.updateCache(containerRoot, folders)
+}
+
+// updateCache adds the libraries in the specified folders to the ld.so.cache in the container.
+// The existing entries of the cache are retained and a cache is created if none exists.
+func (m command) updateCache(root string, folders []string) error {
+	if len(folders) == 0 {
+		m.logger.Debugf("No folders to add to ld.so.cache")
+		return nil
 	}
 
-	return syscall.Exec(args[0], args, nil)
+	entries, err := ldcache.Scan(m.logger, root, folders...)
+	if err != nil {
+		return fmt.Errorf("failed to scan folders: %v", err)
+	}
+	existing, err := ldcache.Entries(m.logger, root)
+	if err != nil {
+		return fmt.Errorf("failed to read existing ld.so.cache: %v", err)
+	}
+
+	etc, err := securejoin.MkdirAllInRoot(root, "/etc", 0755)
+	if errors.Is(err, securejoin.ErrEscape) {
+		m.logger.Warningf("Refusing to update ld.so.cache outside of container root: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create /etc: %v", err)
+	}
+	defer etc.Close()
+
+	m.logger.Debugf("Adding %d libraries from %v to ld.so.cache", len(entries), folders)
+	err = securejoin.ReplaceFile(etc, "ld.so.cache", func(w io.Writer) error {
+		return ldcache.Write(w, append(entries, existing...))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ld.so.cache: %v", err)
+	}
+	return nil
 }
 
 // createConfig creates (or updates) /etc/ld.so.conf.d/nvcr-<RANDOM_STRING>.conf in the container
@@ -95,11 +157,17 @@ func (m command) createConfig(root string, folders []string) error {
 		return nil
 	}
 
-	if err := os.MkdirAll(filepath.Join(root, "/etc/ld.so.conf.d"), 0755); err != nil {
+	configDir, err := securejoin.MkdirAllInRoot(root, "/etc/ld.so.conf.d", 0755)
+	if errors.Is(err, securejoin.ErrEscape) {
+		m.logger.Warningf("Refusing to create ld.so.conf.d outside of container root: %v", err)
+		return nil
+	}
+	if err != nil {
 		return fmt.Errorf("failed to create ld.so.conf.d: %v", err)
 	}
+	defer configDir.Close()
 
-	configFile, err := os.CreateTemp(filepath.Join(root, "/etc/ld.so.conf.d"), "nvcr-*.conf")
+	configFile, err := securejoin.CreateTemp(configDir, "nvcr-", ".conf")
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %v", err)
 	}
@@ -120,7 +188,7 @@ func (m command) createConfig(root string, folders []string) error {
 	}
 
 	// The created file needs to be world readable for the cases where the container is run as a non-root user.
-	if err := os.Chmod(configFile.Name(), 0644); err != nil {
+	if err := configFile.Chmod(0644); err != nil {
 		return fmt.Errorf("failed to chmod config file: %v", err)
 	}
 
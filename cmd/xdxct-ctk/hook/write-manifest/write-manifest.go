@@ -0,0 +1,260 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	manifestDir  = "/run/xdxct"
+	manifestName = "injected.json"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type config struct {
+	devices       cli.StringSlice
+	libraries     cli.StringSlice
+	binaries      cli.StringSlice
+	containerSpec string
+}
+
+// manifest describes the entities injected into a container.
+type manifest struct {
+	DriverVersion  string   `json:"driverVersion,omitempty"`
+	ToolkitVersion string   `json:"toolkitVersion,omitempty"`
+	Devices        []device `json:"devices,omitempty"`
+	Libraries      []string `json:"libraries,omitempty"`
+	Binaries       []string `json:"binaries,omitempty"`
+}
+
+// device describes an injected GPU.
+type device struct {
+	UUID     string `json:"uuid,omitempty"`
+	PCIBusID string `json:"pciBusID,omitempty"`
+}
+
+// NewCommand constructs a write-manifest command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build the write-manifest command
+func (m command) build() *cli.Command {
+	cfg := config{}
+
+	// Create the 'write-manifest' command
+	c := cli.Command{
+		Name:  "write-manifest",
+		Usage: "Record the injected devices, libraries and binaries in " + filepath.Join(manifestDir, manifestName) + " in the container. Entries are merged with an existing manifest.",
+		Before: func(c *cli.Context) error {
+			return validateFlags(c, &cfg)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &cfg)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "device",
+			Usage:       "Specify an injected device as 'uuid=<UUID>,pci=<PCI_BUS_ID>'",
+			Destination: &cfg.devices,
+		},
+		&cli.StringSliceFlag{
+			Name:        "library",
+			Usage:       "Specify the container path of an injected library",
+			Destination: &cfg.libraries,
+		},
+		&cli.StringSliceFlag{
+			Name:        "binary",
+			Usage:       "Specify the container path of an injected binary",
+			Destination: &cfg.binaries,
+		},
+		&cli.StringFlag{
+			Name:        "container-spec",
+			Usage:       "Specify the path to the OCI container spec. If empty or '-' the spec will be read from STDIN",
+			Destination: &cfg.containerSpec,
+		},
+	}
+
+	return &c
+}
+
+func validateFlags(c *cli.Context, cfg *config) error {
+	for _, d := range cfg.devices.Value() {
+		if _, err := parseDevice(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, cfg *config) error {
+	s, err := oci.LoadContainerState(cfg.containerSpec)
+	if err != nil {
+		return fmt.Errorf("failed to load container state: %v", err)
+	}
+	logger.AddFields(m.logger, s.LogFields())
+
+	containerRoot, err := s.GetContainerRoot()
+	if err != nil {
+		return fmt.Errorf("failed to determined container root: %v", err)
+	}
+	if containerRoot == "" {
+		return fmt.Errorf("empty container root detected")
+	}
+
+	update := manifest{
+		ToolkitVersion: info.GetVersionParts()[0],
+		Libraries:      cfg.libraries.Value(),
+		Binaries:       cfg.binaries.Value(),
+	}
+	// The hook is run on the host and the version of the loaded driver is used.
+	if version, err := info.GetDriverVersion("/"); err != nil {
+		m.logger.Warningf("Failed to determine driver version: %v", err)
+	} else {
+		update.DriverVersion = version
+	}
+	for _, d := range cfg.devices.Value() {
+		device, _ := parseDevice(d)
+		update.Devices = append(update.Devices, device)
+	}
+
+	dir, err := securejoin.MkdirAllInRoot(containerRoot, manifestDir, 0755)
+	if errors.Is(err, securejoin.ErrEscape) {
+		m.logger.Warningf("Refusing to write manifest outside of container root: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %v: %v", manifestDir, err)
+	}
+	defer dir.Close()
+
+	existing, err := readManifest(dir, manifestName)
+	if err != nil {
+		return err
+	}
+
+	merged := existing.merge(update)
+	m.logger.Debugf("Writing manifest with %d devices, %d libraries and %d binaries", len(merged.Devices), len(merged.Libraries), len(merged.Binaries))
+	err = securejoin.ReplaceFile(dir, manifestName, func(w io.Writer) error {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(merged)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return nil
+}
+
+// parseDevice parses a device specified as a comma-separated list of key=value pairs.
+// The supported keys are 'uuid' and 'pci'.
+func parseDevice(value string) (device, error) {
+	var d device
+	for _, field := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return device{}, fmt.Errorf("invalid device %q: expected key=value pairs", value)
+		}
+		switch key {
+		case "uuid":
+			d.UUID = val
+		case "pci":
+			d.PCIBusID = val
+		default:
+			return device{}, fmt.Errorf("invalid device %q: unknown key %q", value, key)
+		}
+	}
+	if d == (device{}) {
+		return device{}, fmt.Errorf("invalid device %q: a UUID or PCI bus ID is required", value)
+	}
+	return d, nil
+}
+
+// readManifest reads the manifest with the specified name from the directory. An empty
+// manifest is returned if the file does not exist.
+func readManifest(dir *os.File, name string) (manifest, error) {
+	fd, err := unix.Openat(int(dir.Fd()), name, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if errors.Is(err, unix.ENOENT) {
+		return manifest{}, nil
+	}
+	if err != nil {
+		return manifest{}, fmt.Errorf("failed to open %v: %v", name, err)
+	}
+	f := os.NewFile(uintptr(fd), filepath.Join(dir.Name(), name))
+	defer f.Close()
+
+	var m manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil && err != io.EOF {
+		return manifest{}, fmt.Errorf("failed to decode %v: %v", name, err)
+	}
+	return m, nil
+}
+
+// merge returns the manifest with the entries of the update added. Versions in the update take
+// precedence if set and the lists are deduplicated and sorted.
+func (m manifest) merge(update manifest) manifest {
+	merged := manifest{
+		DriverVersion:  m.DriverVersion,
+		ToolkitVersion: m.ToolkitVersion,
+	}
+	if update.DriverVersion != "" {
+		merged.DriverVersion = update.DriverVersion
+	}
+	if update.ToolkitVersion != "" {
+		merged.ToolkitVersion = update.ToolkitVersion
+	}
+
+	seen := make(map[device]bool)
+	for _, d := range append(append([]device{}, m.Devices...), update.Devices...) {
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		merged.Devices = append(merged.Devices, d)
+	}
+	sort.SliceStable(merged.Devices, func(i, j int) bool {
+		return merged.Devices[i].PCIBusID < merged.Devices[j].PCIBusID
+	})
+
+	merged.Libraries = uniqueSorted(m.Libraries, update.Libraries)
+	merged.Binaries = uniqueSorted(m.Binaries, update.Binaries)
+	return merged
+}
+
+// uniqueSorted returns the sorted set of the specified paths.
+func uniqueSorted(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, list := range lists {
+		for _, p := range list {
+			if p == "" || seen[p] {
+				continue
+			}
+			seen[p] = true
+			unique = append(unique, p)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDevice(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expected      device
+		expectedError bool
+	}{
+		{value: "uuid=GPU-1234,pci=0000:01:00.0", expected: device{UUID: "GPU-1234", PCIBusID: "0000:01:00.0"}},
+		{value: "pci=0000:01:00.0", expected: device{PCIBusID: "0000:01:00.0"}},
+		{value: "uuid=", expectedError: true},
+		{value: "0000:01:00.0", expectedError: true},
+		{value: "minor=0", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			d, err := parseDevice(tc.value)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, d)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	existing := manifest{
+		DriverVersion:  "1.0",
+		ToolkitVersion: "0.1",
+		Devices:        []device{{UUID: "GPU-2", PCIBusID: "0000:02:00.0"}},
+		Libraries:      []string{"/usr/lib/libxdxgpu-ml.so.1"},
+	}
+	update := manifest{
+		ToolkitVersion: "0.2",
+		Devices: []device{
+			{UUID: "GPU-2", PCIBusID: "0000:02:00.0"},
+			{UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+		},
+		Libraries: []string{"/usr/lib/libdrm_xdxgpu.so", "/usr/lib/libxdxgpu-ml.so.1"},
+		Binaries:  []string{"/usr/bin/xdxsmi"},
+	}
+
+	require.Equal(t,
+		manifest{
+			DriverVersion:  "1.0",
+			ToolkitVersion: "0.2",
+			Devices: []device{
+				{UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+				{UUID: "GPU-2", PCIBusID: "0000:02:00.0"},
+			},
+			Libraries: []string{"/usr/lib/libdrm_xdxgpu.so", "/usr/lib/libxdxgpu-ml.so.1"},
+			Binaries:  []string{"/usr/bin/xdxsmi"},
+		},
+		existing.merge(update),
+	)
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	d, err := os.Open(dir)
+	require.NoError(t, err)
+	defer d.Close()
+
+	m, err := readManifest(d, manifestName)
+	require.NoError(t, err)
+	require.Equal(t, manifest{}, m)
+
+	require.NoError(t, os.WriteFile(dir+"/"+manifestName, []byte(`{"driverVersion":"1.0","libraries":["/usr/lib/libdrm.so"]}`), 0644))
+	m, err = readManifest(d, manifestName)
+	require.NoError(t, err)
+	require.Equal(t, manifest{DriverVersion: "1.0", Libraries: []string{"/usr/lib/libdrm.so"}}, m)
+
+	require.NoError(t, os.Symlink("/etc/passwd", dir+"/link.json"))
+	_, err = readManifest(d, "link.json")
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/config"
 	"github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/hook"
 	infoCLI "github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/info"
+	"github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/requirements"
 	"github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/runtime"
 	"github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/system"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/info"
@@ -72,6 +73,7 @@ func main() {
 		cdi.NewCommand(logger),
 		system.NewCommand(logger),
 		config.NewCommand(logger),
+		requirements.NewCommand(logger),
 	}
 
 	// Run the CLI
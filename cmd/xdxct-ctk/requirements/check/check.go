@@ -0,0 +1,156 @@
+package check
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/requirements"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/requirements/constraints"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/xdxml"
+	"github.com/urfave/cli/v2"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+type options struct {
+	requirements cli.StringSlice
+	properties   cli.StringSlice
+}
+
+// NewCommand constructs a check command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+// build the check command
+func (m command) build() *cli.Command {
+	opts := options{}
+
+	c := cli.Command{
+		Name:  "check",
+		Usage: "Evaluate a requirement expression (as specified in an XDXCT_REQUIRE_* envvar) against the current host",
+		Before: func(c *cli.Context) error {
+			return m.validateFlags(c, &opts)
+		},
+		Action: func(c *cli.Context) error {
+			return m.run(c, &opts)
+		},
+	}
+
+	c.Flags = []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "require",
+			Usage:       "Specify a requirement expression to check. This can be specified multiple times and all requirements must be met.",
+			Destination: &opts.requirements,
+		},
+		&cli.StringSliceFlag{
+			Name:        "property",
+			Usage:       "Override a detected host property as name=value. This can be used to check requirements against hosts other than the current one.",
+			Destination: &opts.properties,
+		},
+	}
+
+	return &c
+}
+
+func (m command) validateFlags(c *cli.Context, opts *options) error {
+	if len(opts.requirements.Value()) == 0 {
+		return fmt.Errorf("at least one requirement must be specified")
+	}
+	for _, p := range opts.properties.Value() {
+		if !strings.Contains(p, "=") {
+			return fmt.Errorf("invalid property %q; expected name=value", p)
+		}
+	}
+	return nil
+}
+
+func (m command) run(c *cli.Context, opts *options) error {
+	properties, err := requirements.DetectHostProperties(m.logger, xdxml.New())
+	if err != nil {
+		m.logger.Warningf("Failed to detect device properties: %v", err)
+	}
+	if properties == nil {
+		properties = &requirements.HostProperties{}
+	}
+	if err := applyOverrides(properties, opts.properties.Value()); err != nil {
+		return err
+	}
+
+	r := requirements.New(m.logger, opts.requirements.Value())
+	r.AddHostProperties(properties)
+
+	results, err := r.Evaluate()
+	if err != nil {
+		return fmt.Errorf("failed to evaluate requirements: %v", err)
+	}
+
+	satisfied := true
+	for _, result := range results {
+		m.printResult(result, 0)
+		satisfied = satisfied && result.Satisfied
+	}
+	if !satisfied {
+		return fmt.Errorf("requirements not satisfied")
+	}
+	m.logger.Infof("All requirements satisfied")
+	return nil
+}
+
+// printResult logs the result of a constraint and its sub-expressions.
+func (m command) printResult(result constraints.Result, depth int) {
+	status := "FAIL"
+	if result.Satisfied {
+		status = "OK"
+	}
+
+	line := fmt.Sprintf("%s[%s] %s", strings.Repeat("  ", depth), status, result.Constraint)
+	if result.Reason != "" {
+		line += fmt.Sprintf(" (%s)", result.Reason)
+	}
+	m.logger.Infof("%s", line)
+
+	for _, child := range result.Children {
+		m.printResult(child, depth+1)
+	}
+}
+
+// applyOverrides updates the host properties with the specified name=value pairs.
+func applyOverrides(properties *requirements.HostProperties, overrides []string) error {
+	for _, o := range overrides {
+		parts := strings.SplitN(o, "=", 2)
+		name, value := parts[0], parts[1]
+		switch name {
+		case requirements.ARCH:
+			properties.Arch = value
+		case requirements.DRIVER:
+			properties.Driver = value
+		case requirements.KERNEL:
+			properties.Kernel = value
+		case requirements.PCIID:
+			properties.PCIDeviceID = value
+		case requirements.COUNT:
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %v: %v", name, err)
+			}
+			properties.Count = count
+		case requirements.MEMORY:
+			memory, err := constraints.ParseSize(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %v: %v", name, err)
+			}
+			properties.Memory = uint64(memory)
+		default:
+			return fmt.Errorf("unsupported property %q", name)
+		}
+	}
+	return nil
+}
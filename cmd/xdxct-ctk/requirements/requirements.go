@@ -0,0 +1,33 @@
+package requirements
+
+import (
+	"github.com/XDXCT/xdxct-container-toolkit/cmd/xdxct-ctk/requirements/check"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/urfave/cli/v2"
+)
+
+type command struct {
+	logger logger.Interface
+}
+
+// NewCommand constructs a requirements command with the specified logger
+func NewCommand(logger logger.Interface) *cli.Command {
+	c := command{
+		logger: logger,
+	}
+	return c.build()
+}
+
+func (m command) build() *cli.Command {
+	// Create the 'requirements' command
+	requirements := cli.Command{
+		Name:  "requirements",
+		Usage: "Utilities for working with XDXCT_REQUIRE_* container requirements",
+	}
+
+	requirements.Subcommands = []*cli.Command{
+		check.NewCommand(m.logger),
+	}
+
+	return &requirements
+}
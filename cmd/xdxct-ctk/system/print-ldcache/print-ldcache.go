@@ -2,6 +2,7 @@ package createdevicenodes
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/ldcache"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
@@ -30,7 +31,7 @@ func (m command) build() *cli.Command {
 
 	c := cli.Command{
 		Name:  "print-ldcache",
-		Usage: "A utility to print the contents of the ldcache",
+		Usage: "A utility to print the contents of the ldcache grouped by architecture",
 		Before: func(c *cli.Context) error {
 			return m.validateFlags(c, &opts)
 		},
@@ -62,21 +63,22 @@ func (m command) run(c *cli.Context, opts *options) error {
 		return fmt.Errorf("failed to create ldcache: %v", err)
 	}
 
-	lib32, lib64 := cache.List()
+	libs := cache.List()
+	if len(libs) == 0 {
+		m.logger.Info("No libraries found")
+		return nil
+	}
 
-	if len(lib32) == 0 {
-		m.logger.Info("No 32-bit libraries found")
-	} else {
-		m.logger.Infof("%d 32-bit libraries found", len(lib32))
-		for _, lib := range lib32 {
-			m.logger.Infof("%v", lib)
-		}
+	var arches []string
+	for arch := range libs {
+		arches = append(arches, string(arch))
 	}
-	if len(lib64) == 0 {
-		m.logger.Info("No 64-bit libraries found")
-	} else {
-		m.logger.Infof("%d 64-bit libraries found", len(lib64))
-		for _, lib := range lib64 {
+	sort.Strings(arches)
+
+	for _, arch := range arches {
+		paths := libs[ldcache.Arch(arch)]
+		m.logger.Infof("%d %v libraries found", len(paths), arch)
+		for _, lib := range paths {
 			m.logger.Infof("%v", lib)
 		}
 	}
@@ -86,11 +86,17 @@ func GetDefault() (*Config, error) {
 			Ldconfig:  getLdConfigPath(),
 		},
 		XDXCTCTKConfig: CTKConfig{
-			Path: xdxctCTKExecutable,
+			Path:      xdxctCTKExecutable,
+			LogTarget: "file",
+			HookTimeouts: HookTimeouts{
+				DefaultHookTimeoutKey: 30,
+				"update-ldcache":      120,
+			},
 		},
 		XDXCTContainerRuntimeConfig: RuntimeConfig{
 			DebugFilePath: "/dev/null",
 			LogLevel:      "info",
+			LogTarget:     "file",
 			Runtimes:      []string{"docker-runc", "runc"},
 			Mode:          "auto",
 			Modes: modesConfig{
@@ -103,9 +109,29 @@ func GetDefault() (*Config, error) {
 					SpecDirs:           cdi.DefaultSpecDirs,
 				},
 			},
+			GPUEnvironment: true,
+			MountConflicts: mountConflictsConfig{
+				DefaultPolicy: "replace",
+			},
+			SELinux: selinuxConfig{
+				Labeling:     "disabled",
+				RelabelPaths: []string{"/var/run/xdxct-container-devices"},
+			},
+			ContainerLogging: containerLoggingConfig{
+				Annotations: []string{"xdxct.com/runtime-log-level"},
+				Dir:         "/var/log/xdxct-container-runtime",
+			},
+			LowLevelRuntimes: lowLevelRuntimesConfig{
+				Annotations: []string{
+					"xdxct.com/low-level-runtime",
+					"io.kubernetes.cri.runtime-handler",
+					"io.kubernetes.cri-o.RuntimeHandler",
+				},
+			},
 		},
 		XDXCTContainerRuntimeHookConfig: RuntimeHookConfig{
-			Path: XDXCTContainerRuntimeHookExecutable,
+			Path:      XDXCTContainerRuntimeHookExecutable,
+			Lifecycle: "prestart",
 		},
 	}
 	return &d, nil
@@ -7,6 +7,12 @@ type RuntimeHookConfig struct {
 	Path string `toml:"path"`
 	// SkipModeDetection disables the mode check for the runtime hook.
 	SkipModeDetection bool `toml:"skip-mode-detection"`
+	// Lifecycle specifies the lifecycle at which the hook is inserted in the legacy mode.
+	// One of prestart, createRuntime, or createContainer.
+	Lifecycle string `toml:"lifecycle"`
+	// VerifyDevices enables the check that the requested devices are visible in
+	// the container. In the legacy mode the hook is then also inserted as a poststart hook.
+	VerifyDevices bool `toml:"verify-devices"`
 }
 
 // GetDefaultRuntimeHookConfig defines the default values for the config
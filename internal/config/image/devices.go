@@ -20,6 +20,10 @@ var _ VisibleDevices = (*devices)(nil)
 func NewVisibleDevices(envvars ...string) VisibleDevices {
 	for _, envvar := range envvars {
 		if envvar == "all" {
+			// Exclusions such as all,-1 need to be resolved against the available devices.
+			if hasExclusions(envvars...) {
+				return newDevices(envvars...)
+			}
 			return all{}
 		}
 		if envvar == "none" {
@@ -77,6 +81,10 @@ func newDevices(idOrCommaSeparated ...string) devices {
 	i := 0
 	for _, commaSeparated := range idOrCommaSeparated {
 		for _, id := range strings.Split(commaSeparated, ",") {
+			// Repeated IDs would otherwise leave an empty entry in the list.
+			if _, ok := lookup[id]; ok {
+				continue
+			}
 			lookup[id] = i
 			i++
 		}
@@ -109,3 +117,15 @@ func (d devices) Has(id string) bool {
 	_, exist := d.lookup[id]
 	return exist
 }
+
+// hasExclusions checks whether any of the specified devices is an exclusion (e.g. -1)
+func hasExclusions(idOrCommaSeparated ...string) bool {
+	for _, commaSeparated := range idOrCommaSeparated {
+		for _, id := range strings.Split(commaSeparated, ",") {
+			if strings.HasPrefix(strings.TrimSpace(id), "-") {
+				return true
+			}
+		}
+	}
+	return false
+}
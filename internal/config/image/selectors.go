@@ -0,0 +1,277 @@
+package image
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/pkg/parser"
+)
+
+// Device describes a device that is available on the system. Device selectors
+// such as ranges or attributes are resolved against a list of such devices.
+type Device struct {
+	Index    int
+	UUID     string
+	Arch     string
+	PCIBusID string
+}
+
+// DeviceLister is used to query the devices available on the system.
+type DeviceLister interface {
+	ListDevices() ([]Device, error)
+}
+
+// The following attributes are supported in attribute selectors
+const (
+	selectorAttributeArch  = "arch"
+	selectorAttributePCI   = "pci"
+	selectorAttributeUUID  = "uuid"
+	selectorAttributeCount = "count"
+)
+
+// ResolveVisibleDevices resolves the device selectors in the requested devices against the
+// devices returned by the specified lister. The following selectors are supported in
+// addition to device indices, UUIDs, and fully-qualified CDI device names:
+//
+//	0-3              a range of device indices (inclusive)
+//	-2, -0-1         exclude a previously selected device or range
+//	arch=X2          devices matching an attribute; arch, pci, and uuid are
+//	                 supported and values may be glob patterns. Multiple
+//	                 attributes are ANDed together.
+//	count=2          limit the number of devices matched by attribute selectors
+//
+// If no selectors that require the available devices are present, the
+// requested devices are returned unchanged and the lister is not queried.
+// Resolved devices are returned as device indices.
+func ResolveVisibleDevices(requested VisibleDevices, lister DeviceLister) (VisibleDevices, error) {
+	if _, ok := requested.(devices); !ok {
+		return requested, nil
+	}
+
+	selectors := requested.List()
+	if !requiresResolution(selectors) {
+		return requested, nil
+	}
+
+	if lister == nil {
+		return nil, fmt.Errorf("no device lister available to resolve device selectors %v", selectors)
+	}
+	available, err := lister.ListDevices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available devices: %v", err)
+	}
+
+	resolved, err := resolveSelectors(selectors, available)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved) == 0 {
+		return nil, fmt.Errorf("device selectors %v do not select any device", selectors)
+	}
+
+	return NewVisibleDevices(strings.Join(resolved, ",")), nil
+}
+
+// requiresResolution checks whether any of the selectors need to be resolved
+// against the list of available devices.
+func requiresResolution(selectors []string) bool {
+	for _, s := range selectors {
+		if parser.IsQualifiedName(s) {
+			continue
+		}
+		if strings.HasPrefix(s, "-") || strings.Contains(s, "=") {
+			return true
+		}
+		if _, _, isRange := parseRange(s); isRange {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSelectors applies the selectors in order to construct the list of selected device indices.
+func resolveSelectors(selectors []string, available []Device) ([]string, error) {
+	var selected []string
+	isSelected := make(map[string]bool)
+	add := func(id string) {
+		if isSelected[id] {
+			return
+		}
+		isSelected[id] = true
+		selected = append(selected, id)
+	}
+
+	var excluded []Device
+	attributes := make(map[string]string)
+	var attributeSelectors []string
+	for _, s := range selectors {
+		switch {
+		case s == "all":
+			for _, d := range available {
+				add(strconv.Itoa(d.Index))
+			}
+		case parser.IsQualifiedName(s):
+			add(s)
+		case strings.HasPrefix(s, "-"):
+			matches, err := matchIdentifier(strings.TrimPrefix(s, "-"), available)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclusion %q: %v", s, err)
+			}
+			excluded = append(excluded, matches...)
+		case strings.Contains(s, "="):
+			parts := strings.SplitN(s, "=", 2)
+			key, value := parts[0], parts[1]
+			if existing, ok := attributes[key]; ok && existing != value {
+				return nil, fmt.Errorf("ambiguous device selectors %v=%v and %v", key, existing, s)
+			}
+			attributes[key] = value
+			attributeSelectors = append(attributeSelectors, s)
+		default:
+			matches, err := matchIdentifier(s, available)
+			if err != nil {
+				return nil, fmt.Errorf("invalid device selector %q: %v", s, err)
+			}
+			for _, d := range matches {
+				add(strconv.Itoa(d.Index))
+			}
+		}
+	}
+
+	if len(attributes) > 0 {
+		matches, err := matchAttributes(attributes, available)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device selector %v: %v", strings.Join(attributeSelectors, ","), err)
+		}
+		for _, d := range matches {
+			add(strconv.Itoa(d.Index))
+		}
+	}
+
+	if len(excluded) > 0 && len(selected) == 0 {
+		return nil, fmt.Errorf("ambiguous device selectors %v: exclusions require devices to be selected first (e.g. all,-1)", selectors)
+	}
+	for _, d := range excluded {
+		delete(isSelected, strconv.Itoa(d.Index))
+	}
+
+	var resolved []string
+	for _, id := range selected {
+		if isSelected[id] {
+			resolved = append(resolved, id)
+		}
+	}
+	return resolved, nil
+}
+
+// matchIdentifier returns the devices matching an index, range, UUID, or PCI bus ID.
+func matchIdentifier(id string, available []Device) ([]Device, error) {
+	if start, end, isRange := parseRange(id); isRange {
+		if start > end {
+			return nil, fmt.Errorf("invalid range %v", id)
+		}
+		var matches []Device
+		for i := start; i <= end; i++ {
+			d, err := deviceByIndex(i, available)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, d)
+		}
+		return matches, nil
+	}
+
+	if index, err := strconv.Atoi(id); err == nil {
+		d, err := deviceByIndex(index, available)
+		if err != nil {
+			return nil, err
+		}
+		return []Device{d}, nil
+	}
+
+	for _, d := range available {
+		if d.UUID == id || d.PCIBusID == id {
+			return []Device{d}, nil
+		}
+	}
+	return nil, fmt.Errorf("no device with UUID or PCI bus ID %v found", id)
+}
+
+// matchAttributes returns the devices that match all the specified attributes.
+// If a count is specified, exactly that number of devices is returned.
+func matchAttributes(attributes map[string]string, available []Device) ([]Device, error) {
+	count := -1
+	if c, ok := attributes[selectorAttributeCount]; ok {
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid count %v", c)
+		}
+		count = n
+	}
+
+	var matches []Device
+	for _, d := range available {
+		matched := true
+		for key, pattern := range attributes {
+			var value string
+			switch key {
+			case selectorAttributeArch:
+				value = d.Arch
+			case selectorAttributePCI:
+				value = d.PCIBusID
+			case selectorAttributeUUID:
+				value = d.UUID
+			case selectorAttributeCount:
+				continue
+			default:
+				return nil, fmt.Errorf("unsupported attribute %q", key)
+			}
+			m, err := path.Match(pattern, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %v for %v: %v", pattern, key, err)
+			}
+			matched = matched && m
+		}
+		if matched {
+			matches = append(matches, d)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no matching devices found")
+	}
+	if count == -1 {
+		return matches, nil
+	}
+	if len(matches) < count {
+		return nil, fmt.Errorf("requested %d devices but only %d match", count, len(matches))
+	}
+	return matches[:count], nil
+}
+
+func deviceByIndex(index int, available []Device) (Device, error) {
+	for _, d := range available {
+		if d.Index == index {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("no device with index %d found", index)
+}
+
+// parseRange parses a range of device indices such as 0-3.
+func parseRange(s string) (int, int, bool) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	start, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
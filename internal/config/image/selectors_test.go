@@ -0,0 +1,130 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type staticLister []Device
+
+func (l staticLister) ListDevices() ([]Device, error) {
+	return l, nil
+}
+
+func TestResolveVisibleDevices(t *testing.T) {
+	available := staticLister{
+		{Index: 0, UUID: "uuid-0", Arch: "X1", PCIBusID: "0000:1a:00.0"},
+		{Index: 1, UUID: "uuid-1", Arch: "X2", PCIBusID: "0000:3b:00.0"},
+		{Index: 2, UUID: "uuid-2", Arch: "X2", PCIBusID: "0000:3b:01.0"},
+		{Index: 3, UUID: "uuid-3", Arch: "X2", PCIBusID: "0000:5e:00.0"},
+	}
+
+	testCases := []struct {
+		description   string
+		envvar        string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			description: "all is not resolved",
+			envvar:      "all",
+			expected:    []string{"all"},
+		},
+		{
+			description: "plain identifiers are not resolved",
+			envvar:      "0,uuid-3,xdxct.com/gpu=1",
+			expected:    []string{"0", "uuid-3", "xdxct.com/gpu=1"},
+		},
+		{
+			description: "range",
+			envvar:      "1-3",
+			expected:    []string{"1", "2", "3"},
+		},
+		{
+			description: "all with exclusion",
+			envvar:      "all,-2",
+			expected:    []string{"0", "1", "3"},
+		},
+		{
+			description: "range with excluded range",
+			envvar:      "0-3,-1-2",
+			expected:    []string{"0", "3"},
+		},
+		{
+			description: "exclusion by uuid",
+			envvar:      "all,-uuid-0",
+			expected:    []string{"1", "2", "3"},
+		},
+		{
+			description: "attribute selector",
+			envvar:      "arch=X2",
+			expected:    []string{"1", "2", "3"},
+		},
+		{
+			description: "attribute selector with count",
+			envvar:      "arch=X2,count=2",
+			expected:    []string{"1", "2"},
+		},
+		{
+			description: "pci glob",
+			envvar:      "pci=0000:3b:*",
+			expected:    []string{"1", "2"},
+		},
+		{
+			description: "attributes are combined",
+			envvar:      "arch=X2,pci=0000:5e:*",
+			expected:    []string{"3"},
+		},
+		{
+			description:   "count exceeds matches",
+			envvar:        "arch=X1,count=2",
+			expectedError: true,
+		},
+		{
+			description:   "unmatched attribute",
+			envvar:        "arch=X9",
+			expectedError: true,
+		},
+		{
+			description:   "unsupported attribute",
+			envvar:        "vendor=foo",
+			expectedError: true,
+		},
+		{
+			description:   "conflicting attributes",
+			envvar:        "arch=X1,arch=X2",
+			expectedError: true,
+		},
+		{
+			description:   "range out of bounds",
+			envvar:        "2-5",
+			expectedError: true,
+		},
+		{
+			description:   "exclusion without selection",
+			envvar:        "-1",
+			expectedError: true,
+		},
+		{
+			description:   "everything excluded",
+			envvar:        "1,-1",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			image, err := New(WithEnvMap(map[string]string{"XDXCT_VISIBLE_DEVICES": tc.envvar}))
+			require.NoError(t, err)
+
+			devices, err := ResolveVisibleDevices(image.DevicesFromEnvvars("XDXCT_VISIBLE_DEVICES"), available)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, devices.List())
+		})
+	}
+}
@@ -5,10 +5,66 @@ type RuntimeConfig struct {
 	DebugFilePath string `toml:"debug"`
 	// LogLevel defines the logging level for the application
 	LogLevel string `toml:"log-level"`
+	// LogTarget defines an additional log target. One of file, journald, or syslog.
+	LogTarget string `toml:"log-target"`
 	// Runtimes defines the candidates for the low-level runtime
 	Runtimes []string    `toml:"runtimes"`
 	Mode     string      `toml:"mode"`
 	Modes    modesConfig `toml:"modes"`
+	// DeviceCgroupRules enables the addition of device cgroup allow rules for injected device nodes
+	DeviceCgroupRules bool `toml:"device-cgroup-rules"`
+	// MountConflicts defines how conflicts between injected mounts and existing container paths are resolved.
+	// This only applies to the legacy mode; in the cdi and jit-cdi modes existing mounts are replaced.
+	MountConflicts mountConflictsConfig `toml:"mount-conflicts"`
+	// SELinux defines how injected mounts and device nodes are labelled on SELinux-enabled hosts
+	SELinux selinuxConfig `toml:"selinux"`
+	// ContainerLogging configures per-container logging requested through annotations
+	ContainerLogging containerLoggingConfig `toml:"container-logging"`
+	// GPUEnvironment enables the environment variables describing the injected GPUs in the container
+	GPUEnvironment bool `toml:"gpu-environment"`
+	// LowLevelRuntimes allows for the low-level runtime to be selected per container
+	LowLevelRuntimes lowLevelRuntimesConfig `toml:"low-level-runtimes"`
+}
+
+// lowLevelRuntimesConfig defines the selection of low-level runtimes per container
+type lowLevelRuntimesConfig struct {
+	// Annotations lists the annotations that select a low-level runtime by name. The first annotation
+	// present in the OCI spec with a value matching a configured runtime is used.
+	Annotations []string `toml:"annotations"`
+	// Runtimes defines the named low-level runtimes that can be selected
+	Runtimes map[string]LowLevelRuntimeConfig `toml:"runtimes"`
+}
+
+// LowLevelRuntimeConfig defines a named low-level runtime
+type LowLevelRuntimeConfig struct {
+	// Path is the path to the runtime executable. If an executable name is specified, this will be resolved in the path.
+	Path string `toml:"path"`
+	// Args are additional arguments passed to the runtime before the subcommand
+	Args []string `toml:"args"`
+}
+
+// containerLoggingConfig defines the per-container logging options
+type containerLoggingConfig struct {
+	// Annotations lists the annotations that may be used to set the log level for a container
+	Annotations []string `toml:"annotations"`
+	// Dir is the directory in which the per-container log files are created
+	Dir string `toml:"dir"`
+}
+
+// selinuxConfig defines the SELinux labeling of injected paths
+type selinuxConfig struct {
+	// Labeling is one of disabled or relabel
+	Labeling string `toml:"labeling"`
+	// RelabelPaths lists the toolkit-owned host directories whose files may be relabelled
+	RelabelPaths []string `toml:"relabel-paths"`
+}
+
+// mountConflictsConfig defines the policy for resolving mount conflicts
+type mountConflictsConfig struct {
+	// DefaultPolicy is one of skip, replace, error, or shadow-with-warning
+	DefaultPolicy string `toml:"default-policy"`
+	// Policies overrides the default policy for container paths matching the specified patterns
+	Policies map[string]string `toml:"policies"`
 }
 
 // modesConfig defines (optional) per-mode configs
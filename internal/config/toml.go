@@ -99,9 +99,13 @@ func (t *Toml) Config() (*Config, error) {
 	if t == nil {
 		return cfg, nil
 	}
+	defaultHookTimeouts := cfg.XDXCTCTKConfig.HookTimeouts
 	if err := t.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %v", err)
 	}
+	// Unmarshalling a table replaces the default map, so the default timeouts for hooks that
+	// are not listed in the config are added back.
+	cfg.XDXCTCTKConfig.HookTimeouts = defaultHookTimeouts.merge(cfg.XDXCTCTKConfig.HookTimeouts)
 	return cfg, nil
 }
 
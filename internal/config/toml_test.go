@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHookTimeouts(t *testing.T) {
+	testCases := []struct {
+		description string
+		contents    string
+		expected    HookTimeouts
+	}{
+		{
+			description: "defaults",
+			expected: HookTimeouts{
+				DefaultHookTimeoutKey: 30,
+				"update-ldcache":      120,
+			},
+		},
+		{
+			description: "entries are merged over the defaults",
+			contents: `
+[xdxct-ctk.hook-timeouts]
+default = 10
+create-symlinks = 5
+`,
+			expected: HookTimeouts{
+				DefaultHookTimeoutKey: 10,
+				"update-ldcache":      120,
+				"create-symlinks":     5,
+			},
+		},
+		{
+			description: "default timeout can be disabled",
+			contents: `
+[xdxct-ctk.hook-timeouts]
+update-ldcache = 0
+`,
+			expected: HookTimeouts{
+				DefaultHookTimeoutKey: 30,
+				"update-ldcache":      0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			toml, err := loadConfigTomlFrom(strings.NewReader(tc.contents))
+			require.NoError(t, err)
+
+			cfg, err := toml.Config()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cfg.XDXCTCTKConfig.HookTimeouts)
+		})
+	}
+}
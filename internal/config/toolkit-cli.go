@@ -1,6 +1,57 @@
 package config
 
+import "path/filepath"
+
+// DefaultHookTimeoutKey is the key in the hook timeouts that applies to hooks without an explicit timeout.
+const DefaultHookTimeoutKey = "default"
+
 // CTKConfig stores the config options for the XDXCT Container Toolkit CLI (xdxct-ctk)
 type CTKConfig struct {
 	Path string `toml:"path"`
+	// LogTarget defines an additional log target for the hooks. One of file, journald, or syslog.
+	LogTarget string `toml:"log-target"`
+	// HookTimeouts defines the timeouts in seconds for the xdxct-ctk hooks injected into a container.
+	HookTimeouts HookTimeouts `toml:"hook-timeouts"`
+}
+
+// HookTimeouts maps the names of xdxct-ctk hooks to their timeouts in seconds. The timeout for
+// the DefaultHookTimeoutKey applies to hooks that are not listed. A timeout of 0 sets no timeout.
+type HookTimeouts map[string]int
+
+// Get returns the timeout for the hook invoked with the specified path and arguments. Nil is
+// returned if this is not an xdxct-ctk hook or if no timeout is configured.
+func (t HookTimeouts) Get(path string, args []string) *int {
+	name := GetHookName(path, args)
+	if name == "" {
+		return nil
+	}
+	timeout, ok := t[name]
+	if !ok {
+		timeout = t[DefaultHookTimeoutKey]
+	}
+	if timeout <= 0 {
+		return nil
+	}
+	return &timeout
+}
+
+// merge returns the timeouts with the specified overrides applied.
+func (t HookTimeouts) merge(overrides HookTimeouts) HookTimeouts {
+	merged := make(HookTimeouts)
+	for name, timeout := range t {
+		merged[name] = timeout
+	}
+	for name, timeout := range overrides {
+		merged[name] = timeout
+	}
+	return merged
+}
+
+// GetHookName returns the name of the xdxct-ctk hook invoked with the specified path and
+// arguments. An empty string is returned if these do not invoke an xdxct-ctk hook.
+func GetHookName(path string, args []string) string {
+	if filepath.Base(path) != xdxctCTKExecutable || len(args) < 3 || args[1] != "hook" {
+		return ""
+	}
+	return args[2]
 }
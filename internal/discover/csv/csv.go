@@ -0,0 +1,90 @@
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MountSpecType defines the mount types allowed in a CSV file
+type MountSpecType string
+
+const (
+	// MountSpecDev is used for device nodes
+	MountSpecDev = MountSpecType("dev")
+	// MountSpecLib is used for libraries or regular files
+	MountSpecLib = MountSpecType("lib")
+	// MountSpecSym is used for symlinks
+	MountSpecSym = MountSpecType("sym")
+	// MountSpecDir is used for directories
+	MountSpecDir = MountSpecType("dir")
+)
+
+// MountSpec represents a single entry in a CSV file
+type MountSpec struct {
+	Type MountSpecType
+	Path string
+}
+
+// ParseFile parses the mount specs in the specified CSV file.
+func ParseFile(filename string) ([]*MountSpec, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %v: %v", filename, err)
+	}
+	defer f.Close()
+
+	mountSpecs, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", filename, err)
+	}
+	return mountSpecs, nil
+}
+
+// Parse parses the mount specs in the CSV content read from the specified reader. Each
+// non-empty line that is not a comment is expected to have the form 'type, path'.
+func Parse(r io.Reader) ([]*MountSpec, error) {
+	var mountSpecs []*MountSpec
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		mountSpec, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		mountSpecs = append(mountSpecs, mountSpec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return mountSpecs, nil
+}
+
+func parseLine(line string) (*MountSpec, error) {
+	parts := strings.SplitN(line, ",", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("unexpected number of parts in %q", line)
+	}
+
+	mountSpecType := MountSpecType(strings.TrimSpace(parts[0]))
+	switch mountSpecType {
+	case MountSpecDev, MountSpecLib, MountSpecSym, MountSpecDir:
+	default:
+		return nil, fmt.Errorf("unexpected mount type %q", mountSpecType)
+	}
+
+	path := strings.TrimSpace(parts[1])
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path %q is not absolute", path)
+	}
+
+	return &MountSpec{Type: mountSpecType, Path: filepath.Clean(path)}, nil
+}
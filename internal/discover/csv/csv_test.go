@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description   string
+		contents      string
+		expected      []*MountSpec
+		expectedError bool
+	}{
+		{
+			description: "empty file",
+		},
+		{
+			description: "comments and empty lines are skipped",
+			contents:    "# comment\n\n   \n",
+		},
+		{
+			description: "all mount types",
+			contents: `dev, /dev/xdxct0
+lib, /usr/lib/libxdxct.so.1
+sym,/usr/lib/libxdxct.so
+dir, /usr/share/xdxct/
+`,
+			expected: []*MountSpec{
+				{Type: MountSpecDev, Path: "/dev/xdxct0"},
+				{Type: MountSpecLib, Path: "/usr/lib/libxdxct.so.1"},
+				{Type: MountSpecSym, Path: "/usr/lib/libxdxct.so"},
+				{Type: MountSpecDir, Path: "/usr/share/xdxct"},
+			},
+		},
+		{
+			description:   "missing path",
+			contents:      "sym\n",
+			expectedError: true,
+		},
+		{
+			description:   "invalid type",
+			contents:      "link, /usr/lib/libxdxct.so\n",
+			expectedError: true,
+		},
+		{
+			description:   "relative path",
+			contents:      "sym, usr/lib/libxdxct.so\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			mountSpecs, err := Parse(strings.NewReader(tc.contents))
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expected, mountSpecs)
+		})
+	}
+}
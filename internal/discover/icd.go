@@ -0,0 +1,148 @@
+package discover
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup/root"
+)
+
+// icd describes the ICD (or vendor) files that allow the loader of an API to find the driver.
+type icd struct {
+	// name is the name of the API and is also used for the hook argument.
+	name       string
+	capability image.DriverCapability
+	// searchPaths are the directories in which ICD files are located on the host.
+	searchPaths []string
+	// patterns match the XDXCT ICD files in the search paths.
+	patterns []string
+	// library matches the filename of the driver library that an ICD file points to.
+	library string
+}
+
+// icds defines the ICD files that are discovered for the supported APIs.
+var icds = []icd{
+	{
+		name:        "vulkan",
+		capability:  image.DriverCapabilityGraphics,
+		searchPaths: []string{"/usr/share/vulkan/icd.d"},
+		patterns:    []string{"*xdx*.json"},
+		library:     "libvlk_xdxgpu.so*",
+	},
+	{
+		name:        "egl",
+		capability:  image.DriverCapabilityGraphics,
+		searchPaths: []string{"/usr/share/glvnd/egl_vendor.d"},
+		patterns:    []string{"*xdx*.json"},
+		library:     "libEGL_*.so*",
+	},
+	{
+		name:        "opencl",
+		capability:  image.DriverCapabilityCompute,
+		searchPaths: []string{"/etc/OpenCL/vendors"},
+		patterns:    []string{"*xdx*.icd"},
+		library:     "libOpenCL*.so*",
+	},
+}
+
+type icdFiles struct {
+	None
+	logger       logger.Interface
+	xdxctCTKPath string
+	libraries    Discover
+	icds         []icd
+	hostFiles    []Discover
+}
+
+var _ Discover = (*icdFiles)(nil)
+
+// NewICDDiscoverer creates a discoverer for the Vulkan, EGL and OpenCL ICD files required by the
+// specified capabilities. ICD files on the host are mounted into the container. For APIs where
+// the host has no ICD files, a hook is created to generate these pointing at the libraries
+// mounted by the specified discoverer.
+func NewICDDiscoverer(logger logger.Interface, driver *root.Driver, xdxctCTKPath string, libraries Discover, capabilities image.DriverCapabilities) Discover {
+	d := icdFiles{
+		logger:       logger,
+		xdxctCTKPath: xdxctCTKPath,
+		libraries:    libraries,
+	}
+	for _, i := range icds {
+		if !capabilities.Has(i.capability) {
+			continue
+		}
+		hostFiles := NewMounts(
+			logger,
+			lookup.NewFileLocator(
+				lookup.WithLogger(logger),
+				lookup.WithRoot(driver.Root),
+				lookup.WithSearchPaths(i.searchPaths...),
+				lookup.WithOptional(true),
+			),
+			driver.Root,
+			i.patterns,
+		)
+		d.icds = append(d.icds, i)
+		d.hostFiles = append(d.hostFiles, hostFiles)
+	}
+	return &d
+}
+
+// Mounts returns the ICD files located on the host.
+func (d icdFiles) Mounts() ([]Mount, error) {
+	return Merge(d.hostFiles...).Mounts()
+}
+
+// Hooks returns a hook to create the ICD files for the APIs where none were located on the host.
+func (d icdFiles) Hooks() ([]Hook, error) {
+	libraries, err := d.libraries.Mounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover libraries for ICD files: %v", err)
+	}
+	var paths []string
+	for _, m := range libraries {
+		paths = append(paths, m.Path)
+	}
+	sort.Strings(paths)
+
+	var args []string
+	for i, icd := range d.icds {
+		hostFiles, err := d.hostFiles[i].Mounts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover %v ICD files: %v", icd.name, err)
+		}
+		if len(hostFiles) > 0 {
+			continue
+		}
+		library := icd.findLibrary(paths)
+		if library == "" {
+			d.logger.Debugf("No %v ICD files or libraries found; skipping", icd.name)
+			continue
+		}
+		d.logger.Debugf("No %v ICD files found; generating ICD file for %v", icd.name, library)
+		args = append(args, "--"+icd.name+"-library", library)
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	hook := CreateXdxctCTKHook(
+		d.xdxctCTKPath,
+		"create-icd-files",
+		args...,
+	)
+	return []Hook{hook}, nil
+}
+
+// findLibrary returns the first of the specified paths that matches the library of the ICD.
+func (i icd) findLibrary(paths []string) string {
+	for _, p := range paths {
+		if match, _ := filepath.Match(i.library, filepath.Base(p)); match {
+			return p
+		}
+	}
+	return ""
+}
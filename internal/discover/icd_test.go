@@ -0,0 +1,95 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup/root"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestICDDiscoverer(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	libraries := &DiscoverMock{
+		MountsFunc: func() ([]Mount, error) {
+			return []Mount{
+				{Path: "/usr/lib/x86_64-linux-gnu/xdxgpu/libvlk_xdxgpu.so"},
+				{Path: "/usr/lib/x86_64-linux-gnu/xdxgpu/libEGL_mesa.so.0"},
+				{Path: "/usr/lib/x86_64-linux-gnu/xdxgpu/libOpenCL.so.1"},
+				{Path: "/usr/bin/xdxsmi"},
+			}, nil
+		},
+	}
+
+	testCases := []struct {
+		description    string
+		hostFiles      []string
+		capabilities   string
+		expectedMounts []string
+		expectedArgs   []string
+	}{
+		{
+			description:  "no host files",
+			capabilities: "all",
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-icd-files",
+				"--vulkan-library", "/usr/lib/x86_64-linux-gnu/xdxgpu/libvlk_xdxgpu.so",
+				"--egl-library", "/usr/lib/x86_64-linux-gnu/xdxgpu/libEGL_mesa.so.0",
+				"--opencl-library", "/usr/lib/x86_64-linux-gnu/xdxgpu/libOpenCL.so.1",
+			},
+		},
+		{
+			description:    "host files are mounted",
+			hostFiles:      []string{"/usr/share/vulkan/icd.d/xdxgpu_icd.json", "/usr/share/vulkan/icd.d/other_icd.json"},
+			capabilities:   "graphics",
+			expectedMounts: []string{"/usr/share/vulkan/icd.d/xdxgpu_icd.json"},
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-icd-files",
+				"--egl-library", "/usr/lib/x86_64-linux-gnu/xdxgpu/libEGL_mesa.so.0",
+			},
+		},
+		{
+			description:    "compute only",
+			hostFiles:      []string{"/etc/OpenCL/vendors/xdxgpu.icd"},
+			capabilities:   "compute",
+			expectedMounts: []string{"/etc/OpenCL/vendors/xdxgpu.icd"},
+		},
+		{
+			description:  "utility only",
+			capabilities: "utility",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			driverRoot := t.TempDir()
+			for _, f := range tc.hostFiles {
+				require.NoError(t, os.MkdirAll(filepath.Join(driverRoot, filepath.Dir(f)), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(driverRoot, f), []byte("{}"), 0644))
+			}
+			driver := root.New(logger, driverRoot, nil)
+
+			d := NewICDDiscoverer(logger, driver, testXdxctCTKPath, libraries, image.NewDriverCapabilities(tc.capabilities))
+
+			mounts, err := d.Mounts()
+			require.NoError(t, err)
+			var paths []string
+			for _, m := range mounts {
+				paths = append(paths, m.Path)
+			}
+			require.ElementsMatch(t, tc.expectedMounts, paths)
+
+			hooks, err := d.Hooks()
+			require.NoError(t, err)
+			if tc.expectedArgs == nil {
+				require.Empty(t, hooks)
+				return
+			}
+			require.Equal(t, []Hook{{Path: testXdxctCTKPath, Args: tc.expectedArgs, Lifecycle: "createContainer"}}, hooks)
+		})
+	}
+}
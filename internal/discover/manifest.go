@@ -0,0 +1,58 @@
+package discover
+
+import (
+	"fmt"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+)
+
+type manifest struct {
+	None
+	logger       logger.Interface
+	xdxctCTKPath string
+	libraries    Discover
+	binaries     Discover
+}
+
+// NewManifestHook creates a discoverer for a hook that records the libraries and binaries
+// mounted by the specified discoverers in the injection manifest of the container.
+func NewManifestHook(logger logger.Interface, xdxctCTKPath string, libraries Discover, binaries Discover) Discover {
+	return &manifest{
+		logger:       logger,
+		xdxctCTKPath: xdxctCTKPath,
+		libraries:    libraries,
+		binaries:     binaries,
+	}
+}
+
+// Hooks returns a write-manifest hook for the discovered libraries and binaries.
+func (d manifest) Hooks() ([]Hook, error) {
+	libraries, err := d.libraries.Mounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover libraries for manifest: %v", err)
+	}
+	binaries, err := d.binaries.Mounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover binaries for manifest: %v", err)
+	}
+
+	var args []string
+	for _, l := range getLibraryPaths(libraries) {
+		args = append(args, "--library", l)
+	}
+	for _, b := range binaries {
+		args = append(args, "--binary", b.Path)
+	}
+
+	return []Hook{CreateWriteManifestHook(d.xdxctCTKPath, args...)}, nil
+}
+
+// CreateWriteManifestHook creates a hook that records injected entities in the injection
+// manifest of the container.
+func CreateWriteManifestHook(xdxctCTKPath string, args ...string) Hook {
+	return CreateXdxctCTKHook(
+		xdxctCTKPath,
+		"write-manifest",
+		args...,
+	)
+}
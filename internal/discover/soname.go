@@ -0,0 +1,111 @@
+package discover
+
+import (
+	"debug/elf"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+)
+
+type sonameSymlinks struct {
+	None
+	logger       logger.Interface
+	xdxctCTKPath string
+	libraries    Discover
+	devSymlinks  bool
+}
+
+// NewSonameSymlinksHook creates a discoverer for a hook that creates the SONAME symlinks for the
+// libraries mounted by the specified discoverer. The SONAME is read from the library on the host
+// so that the links do not depend on ldconfig being run in the container. The development (.so)
+// symlinks are only created if devSymlinks is set since these are not needed at runtime.
+func NewSonameSymlinksHook(logger logger.Interface, libraries Discover, xdxctCTKPath string, devSymlinks bool) Discover {
+	return &sonameSymlinks{
+		logger:       logger,
+		xdxctCTKPath: xdxctCTKPath,
+		libraries:    libraries,
+		devSymlinks:  devSymlinks,
+	}
+}
+
+// Hooks returns a create-symlinks hook for the SONAME and, if enabled, the development links of
+// the libraries. Links at paths that are also mounted are skipped.
+func (d sonameSymlinks) Hooks() ([]Hook, error) {
+	mounts, err := d.libraries.Mounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover libraries for SONAME symlinks: %v", err)
+	}
+
+	mounted := make(map[string]bool)
+	for _, m := range mounts {
+		mounted[filepath.Clean(m.Path)] = true
+	}
+
+	var links []string
+	seen := make(map[string]bool)
+	addLink := func(target string, link string) {
+		if mounted[link] || seen[link] {
+			return
+		}
+		seen[link] = true
+		links = append(links, fmt.Sprintf("%v::%v", target, link))
+	}
+
+	for _, m := range mounts {
+		if !isLibName(m.Path) {
+			continue
+		}
+		soname, err := getSoname(m.HostPath)
+		if err != nil {
+			d.logger.Debugf("Skipping SONAME symlinks for %v: %v", m.HostPath, err)
+			continue
+		}
+		if soname == "" {
+			continue
+		}
+
+		dir := filepath.Dir(filepath.Clean(m.Path))
+		filename := filepath.Base(m.Path)
+		if soname != filename {
+			addLink(filename, filepath.Join(dir, soname))
+		}
+		if !d.devSymlinks {
+			continue
+		}
+		if devName := getDevName(soname); devName != soname && devName != filename {
+			addLink(soname, filepath.Join(dir, devName))
+		}
+	}
+
+	return CreateCreateSymlinkHook(d.xdxctCTKPath, links).Hooks()
+}
+
+// getSoname returns the DT_SONAME of the specified ELF shared library. An empty string is
+// returned if the library has no SONAME.
+func getSoname(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sonames, err := f.DynString(elf.DT_SONAME)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SONAME: %v", err)
+	}
+	if len(sonames) == 0 {
+		return "", nil
+	}
+	return sonames[0], nil
+}
+
+// getDevName returns the name of the development link for the specified SONAME. This is the
+// SONAME with the version suffix removed; libxdxgpu-ml.so.1 -> libxdxgpu-ml.so, for example.
+func getDevName(soname string) string {
+	if i := strings.Index(soname, ".so."); i >= 0 {
+		return soname[:i+len(".so")]
+	}
+	return soname
+}
@@ -0,0 +1,117 @@
+package discover
+
+import (
+	"debug/elf"
+	"path/filepath"
+	"testing"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/test"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSonameSymlinksHook(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	hostRoot := t.TempDir()
+
+	libraries := map[string]string{
+		"libxdxgpu-ml.so.1.2.3": "libxdxgpu-ml.so.1",
+		"libdrm_xdxgpu.so":      "libdrm_xdxgpu.so.2",
+		"libOpenCL.so.1":        "libOpenCL.so.1",
+		"libnosoname.so.1":      "",
+	}
+	for name, soname := range libraries {
+		require.NoError(t, test.WriteSharedLibrary(filepath.Join(hostRoot, name), elf.ELFCLASS64, elf.EM_X86_64, 0, soname))
+	}
+
+	testCases := []struct {
+		description  string
+		mounts       []string
+		devSymlinks  bool
+		expectedArgs []string
+	}{
+		{
+			description: "no mounts",
+		},
+		{
+			description: "soname and dev links",
+			devSymlinks: true,
+			mounts:      []string{"libxdxgpu-ml.so.1.2.3"},
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-symlinks",
+				"--link", "libxdxgpu-ml.so.1.2.3::/usr/lib/xdxgpu/libxdxgpu-ml.so.1",
+				"--link", "libxdxgpu-ml.so.1::/usr/lib/xdxgpu/libxdxgpu-ml.so",
+			},
+		},
+		{
+			description: "dev name is mounted",
+			mounts:      []string{"libdrm_xdxgpu.so"},
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-symlinks",
+				"--link", "libdrm_xdxgpu.so::/usr/lib/xdxgpu/libdrm_xdxgpu.so.2",
+			},
+		},
+		{
+			description: "soname is mounted",
+			devSymlinks: true,
+			mounts:      []string{"libOpenCL.so.1"},
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-symlinks",
+				"--link", "libOpenCL.so.1::/usr/lib/xdxgpu/libOpenCL.so",
+			},
+		},
+		{
+			description: "mounted links are skipped",
+			devSymlinks: true,
+			mounts:      []string{"libxdxgpu-ml.so.1.2.3", "libxdxgpu-ml.so.1"},
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-symlinks",
+				"--link", "libxdxgpu-ml.so.1::/usr/lib/xdxgpu/libxdxgpu-ml.so",
+			},
+		},
+		{
+			description: "dev links are disabled",
+			mounts:      []string{"libxdxgpu-ml.so.1.2.3", "libOpenCL.so.1"},
+			expectedArgs: []string{
+				"xdxct-ctk", "hook", "create-symlinks",
+				"--link", "libxdxgpu-ml.so.1.2.3::/usr/lib/xdxgpu/libxdxgpu-ml.so.1",
+			},
+		},
+		{
+			description: "no soname",
+			mounts:      []string{"libnosoname.so.1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var mounts []Mount
+			for _, m := range tc.mounts {
+				hostPath := filepath.Join(hostRoot, m)
+				if _, ok := libraries[m]; !ok {
+					hostPath = filepath.Join(hostRoot, "libxdxgpu-ml.so.1.2.3")
+				}
+				mounts = append(mounts, Mount{HostPath: hostPath, Path: filepath.Join("/usr/lib/xdxgpu", m)})
+			}
+			mountMock := &DiscoverMock{
+				MountsFunc: func() ([]Mount, error) {
+					return mounts, nil
+				},
+			}
+
+			hooks, err := NewSonameSymlinksHook(logger, mountMock, testXdxctCTKPath, tc.devSymlinks).Hooks()
+			require.NoError(t, err)
+			if tc.expectedArgs == nil {
+				require.Empty(t, hooks)
+				return
+			}
+			require.Equal(t, []Hook{{Path: testXdxctCTKPath, Args: tc.expectedArgs, Lifecycle: "createContainer"}}, hooks)
+		})
+	}
+}
+
+func TestGetDevName(t *testing.T) {
+	require.Equal(t, "libxdxgpu-ml.so", getDevName("libxdxgpu-ml.so.1"))
+	require.Equal(t, "libxdxgpu-ml.so", getDevName("libxdxgpu-ml.so"))
+	require.Equal(t, "libfoo-1.2.so", getDevName("libfoo-1.2.so.0"))
+}
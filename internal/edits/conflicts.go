@@ -0,0 +1,199 @@
+package edits
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ociSpecs "github.com/opencontainers/runtime-spec/specs-go"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+)
+
+// MountConflictAction defines how a conflict between an injected mount and an existing
+// mount or file at the same container path is resolved.
+type MountConflictAction string
+
+// The following actions are supported for resolving mount conflicts
+const (
+	// MountConflictSkip keeps the existing mount or file and does not inject the mount.
+	MountConflictSkip = MountConflictAction("skip")
+	// MountConflictReplace injects the mount, removing an existing mount at the same path from
+	// the spec or shadowing a file.
+	MountConflictReplace = MountConflictAction("replace")
+	// MountConflictError fails the modification of the spec.
+	MountConflictError = MountConflictAction("error")
+	// MountConflictShadowWithWarning injects the mount as for replace but logs a warning.
+	MountConflictShadowWithWarning = MountConflictAction("shadow-with-warning")
+)
+
+// MountConflictPolicy defines the actions to take when an injected mount conflicts with
+// an existing mount in the spec or a file in the container root filesystem. The policy only
+// applies to the spec edits of the legacy mode; in the cdi and jit-cdi modes the mounts are
+// injected by the CDI library which always replaces an existing mount at the same path.
+type MountConflictPolicy struct {
+	defaultAction MountConflictAction
+	// patterns are sorted so that more specific patterns are checked first.
+	patterns []string
+	actions  map[string]MountConflictAction
+}
+
+// NewMountConflictPolicy creates a policy from the specified default action and per-pattern
+// actions. Patterns are matched against the container path of a mount using path.Match.
+// Patterns without a '/' are also matched against the base name of the path. If multiple
+// patterns match, the longest pattern is used.
+func NewMountConflictPolicy(defaultAction string, actions map[string]string) (*MountConflictPolicy, error) {
+	if defaultAction == "" {
+		defaultAction = string(MountConflictReplace)
+	}
+	d, err := parseMountConflictAction(defaultAction)
+	if err != nil {
+		return nil, err
+	}
+
+	p := MountConflictPolicy{
+		defaultAction: d,
+		actions:       make(map[string]MountConflictAction),
+	}
+	for pattern, action := range actions {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid mount conflict pattern %q: %v", pattern, err)
+		}
+		a, err := parseMountConflictAction(action)
+		if err != nil {
+			return nil, fmt.Errorf("invalid action for mount conflict pattern %q: %v", pattern, err)
+		}
+		p.patterns = append(p.patterns, pattern)
+		p.actions[pattern] = a
+	}
+	sort.Slice(p.patterns, func(i, j int) bool {
+		if len(p.patterns[i]) != len(p.patterns[j]) {
+			return len(p.patterns[i]) > len(p.patterns[j])
+		}
+		return p.patterns[i] < p.patterns[j]
+	})
+
+	return &p, nil
+}
+
+func parseMountConflictAction(action string) (MountConflictAction, error) {
+	switch a := MountConflictAction(action); a {
+	case MountConflictSkip, MountConflictReplace, MountConflictError, MountConflictShadowWithWarning:
+		return a, nil
+	}
+	return "", fmt.Errorf("unsupported mount conflict action %q", action)
+}
+
+// actionFor returns the action that applies to the specified container path.
+func (p *MountConflictPolicy) actionFor(containerPath string) MountConflictAction {
+	for _, pattern := range p.patterns {
+		if matches(pattern, containerPath) {
+			return p.actions[pattern]
+		}
+	}
+	return p.defaultAction
+}
+
+func matches(pattern string, containerPath string) bool {
+	if m, _ := path.Match(pattern, containerPath); m {
+		return true
+	}
+	if strings.Contains(pattern, "/") {
+		return false
+	}
+	m, _ := path.Match(pattern, path.Base(containerPath))
+	return m
+}
+
+// resolveMountConflicts applies the mount conflict policy to the specified mounts and returns
+// the mounts that should be injected into the spec. Existing mounts that are replaced are
+// removed from the spec.
+func (e *edits) resolveMountConflicts(spec *ociSpecs.Spec, mounts []*specs.Mount) ([]*specs.Mount, error) {
+	if e.mountConflicts == nil {
+		return mounts, nil
+	}
+
+	rootfs := e.getRootfs(spec)
+
+	var resolved []*specs.Mount
+	for _, m := range mounts {
+		conflict := e.findConflict(spec, rootfs, m.ContainerPath)
+		if conflict == "" {
+			resolved = append(resolved, m)
+			continue
+		}
+
+		switch e.mountConflicts.actionFor(m.ContainerPath) {
+		case MountConflictSkip:
+			e.logger.Infof("Skipping mount of %v at %v: conflicts with %v", m.HostPath, m.ContainerPath, conflict)
+			continue
+		case MountConflictError:
+			return nil, fmt.Errorf("mount of %v at %v conflicts with %v", m.HostPath, m.ContainerPath, conflict)
+		case MountConflictShadowWithWarning:
+			e.logger.Warningf("Mount of %v at %v shadows %v", m.HostPath, m.ContainerPath, conflict)
+		default:
+			e.logger.Infof("Mount of %v at %v replaces %v", m.HostPath, m.ContainerPath, conflict)
+			removeMounts(spec, m.ContainerPath)
+		}
+		resolved = append(resolved, m)
+	}
+	return resolved, nil
+}
+
+// findConflict returns a description of the existing mount or file at the specified container path.
+// An empty string is returned if there is no conflict.
+func (e *edits) findConflict(spec *ociSpecs.Spec, rootfs string, containerPath string) string {
+	target := filepath.Clean(containerPath)
+	for _, m := range spec.Mounts {
+		if filepath.Clean(m.Destination) == target {
+			return fmt.Sprintf("existing mount of %v", m.Source)
+		}
+	}
+
+	if rootfs == "" {
+		return ""
+	}
+	// The parent directory is resolved in the container root so that symlinks in the image do
+	// not refer to paths on the host. The path itself is not followed since a dangling link in
+	// the image also conflicts with the mount.
+	dir, err := securejoin.Resolve(rootfs, filepath.Dir(target))
+	if err != nil {
+		e.logger.Debugf("Failed to resolve %v in container root: %v", target, err)
+		return ""
+	}
+	if _, err := os.Lstat(filepath.Join(dir, filepath.Base(target))); err == nil {
+		return "file in container image"
+	} else if !os.IsNotExist(err) {
+		e.logger.Debugf("Failed to check for %v in container root: %v", target, err)
+	}
+	return ""
+}
+
+// removeMounts removes the mounts at the specified container path from the spec.
+func removeMounts(spec *ociSpecs.Spec, containerPath string) {
+	target := filepath.Clean(containerPath)
+	var mounts []ociSpecs.Mount
+	for _, m := range spec.Mounts {
+		if filepath.Clean(m.Destination) == target {
+			continue
+		}
+		mounts = append(mounts, m)
+	}
+	spec.Mounts = mounts
+}
+
+// getRootfs returns the path to the container root filesystem on the host. A relative root
+// path is interpreted relative to the bundle directory.
+func (e *edits) getRootfs(spec *ociSpecs.Spec) string {
+	if spec.Root == nil || spec.Root.Path == "" {
+		return ""
+	}
+	if filepath.IsAbs(spec.Root.Path) {
+		return spec.Root.Path
+	}
+	return filepath.Join(e.bundleDir, spec.Root.Path)
+}
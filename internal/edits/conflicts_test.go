@@ -0,0 +1,147 @@
+package edits
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ociSpecs "github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/discover"
+)
+
+func TestMountConflictPolicy(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	bundleDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(bundleDir, "rootfs/usr/lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(bundleDir, "rootfs/usr/lib/libdrm.so.2"), nil, 0644))
+
+	mounts := &discover.DiscoverMock{
+		MountsFunc: func() ([]discover.Mount, error) {
+			m := []discover.Mount{
+				{HostPath: "/host/usr/lib/libdrm.so.2", Path: "/usr/lib/libdrm.so.2"},
+				{HostPath: "/host/usr/bin/xdxsmi", Path: "/usr/bin/xdxsmi"},
+				{HostPath: "/host/usr/lib/libxdx.so.1", Path: "/usr/lib/libxdx.so.1"},
+			}
+			return m, nil
+		},
+	}
+
+	testCases := []struct {
+		description        string
+		defaultAction      string
+		actions            map[string]string
+		expectedError      bool
+		expectedContainers []string
+		expectedSources    []string
+	}{
+		{
+			description:        "replace removes existing mounts",
+			defaultAction:      "replace",
+			expectedContainers: []string{"/usr/bin/xdxsmi", "/usr/lib/libdrm.so.2", "/usr/lib/libxdx.so.1"},
+			expectedSources:    []string{"/host/usr/bin/xdxsmi", "/host/usr/lib/libdrm.so.2", "/host/usr/lib/libxdx.so.1"},
+		},
+		{
+			description:        "skip drops conflicting mounts",
+			defaultAction:      "skip",
+			expectedContainers: []string{"/usr/bin/xdxsmi", "/usr/lib/libxdx.so.1"},
+			expectedSources:    []string{"/other/xdxsmi", "/host/usr/lib/libxdx.so.1"},
+		},
+		{
+			description:   "error fails on conflict",
+			defaultAction: "error",
+			expectedError: true,
+		},
+		{
+			description:        "shadow-with-warning injects all mounts",
+			defaultAction:      "shadow-with-warning",
+			expectedContainers: []string{"/usr/bin/xdxsmi", "/usr/lib/libdrm.so.2", "/usr/lib/libxdx.so.1"},
+			expectedSources:    []string{"/host/usr/bin/xdxsmi", "/host/usr/lib/libdrm.so.2", "/host/usr/lib/libxdx.so.1"},
+		},
+		{
+			description:        "pattern overrides default action",
+			defaultAction:      "error",
+			actions:            map[string]string{"libdrm.so*": "skip", "/usr/bin/*": "replace"},
+			expectedContainers: []string{"/usr/bin/xdxsmi", "/usr/lib/libxdx.so.1"},
+			expectedSources:    []string{"/host/usr/bin/xdxsmi", "/host/usr/lib/libxdx.so.1"},
+		},
+		{
+			description:        "longest pattern takes precedence",
+			defaultAction:      "replace",
+			actions:            map[string]string{"/usr/*/*": "error", "/usr/lib/libdrm.so.2": "skip", "/usr/bin/xdxsmi": "skip"},
+			expectedContainers: []string{"/usr/bin/xdxsmi", "/usr/lib/libxdx.so.1"},
+			expectedSources:    []string{"/other/xdxsmi", "/host/usr/lib/libxdx.so.1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			policy, err := NewMountConflictPolicy(tc.defaultAction, tc.actions)
+			require.NoError(t, err)
+
+			e, err := NewSpecEdits(logger, mounts, WithMountConflictPolicy(policy), WithBundleDir(bundleDir))
+			require.NoError(t, err)
+
+			spec := &ociSpecs.Spec{
+				Root: &ociSpecs.Root{Path: "rootfs"},
+				Mounts: []ociSpecs.Mount{
+					{Source: "/other/xdxsmi", Destination: "/usr/bin/xdxsmi"},
+				},
+			}
+			err = e.Modify(spec)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var destinations, sources []string
+			for _, m := range spec.Mounts {
+				destinations = append(destinations, m.Destination)
+				sources = append(sources, m.Source)
+			}
+			require.ElementsMatch(t, tc.expectedContainers, destinations)
+			require.ElementsMatch(t, tc.expectedSources, sources)
+		})
+	}
+}
+
+func TestMountConflictPolicyDoesNotFollowLinksToHost(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	// The host directory contains a file at the path of the mount. The container image links
+	// to this directory using an absolute path, which must be resolved in the container root.
+	hostDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "libxdx.so.1"), nil, 0644))
+	rootfs := t.TempDir()
+	require.NoError(t, os.Symlink(hostDir, filepath.Join(rootfs, "opt")))
+
+	mounts := &discover.DiscoverMock{
+		MountsFunc: func() ([]discover.Mount, error) {
+			return []discover.Mount{{HostPath: "/host/libxdx.so.1", Path: "/opt/libxdx.so.1"}}, nil
+		},
+	}
+
+	policy, err := NewMountConflictPolicy("error", nil)
+	require.NoError(t, err)
+	e, err := NewSpecEdits(logger, mounts, WithMountConflictPolicy(policy))
+	require.NoError(t, err)
+
+	spec := &ociSpecs.Spec{Root: &ociSpecs.Root{Path: rootfs}}
+	require.NoError(t, e.Modify(spec))
+	require.Len(t, spec.Mounts, 1)
+}
+
+func TestNewMountConflictPolicyErrors(t *testing.T) {
+	_, err := NewMountConflictPolicy("ignore", nil)
+	require.Error(t, err)
+
+	_, err = NewMountConflictPolicy("skip", map[string]string{"[": "skip"})
+	require.Error(t, err)
+
+	_, err = NewMountConflictPolicy("skip", map[string]string{"*.so": "drop"})
+	require.Error(t, err)
+}
@@ -13,12 +13,32 @@ import (
 
 type edits struct {
 	cdi.ContainerEdits
-	logger logger.Interface
+	logger         logger.Interface
+	mountConflicts *MountConflictPolicy
+	bundleDir      string
+}
+
+// Option defines a functional option for configuring the spec edits.
+type Option func(*edits)
+
+// WithMountConflictPolicy sets the policy used to resolve conflicts between injected mounts and
+// existing mounts or files in the container. If no policy is set, conflicts are not checked.
+func WithMountConflictPolicy(policy *MountConflictPolicy) Option {
+	return func(e *edits) {
+		e.mountConflicts = policy
+	}
+}
+
+// WithBundleDir sets the bundle directory used to resolve a relative container root path.
+func WithBundleDir(bundleDir string) Option {
+	return func(e *edits) {
+		e.bundleDir = bundleDir
+	}
 }
 
 // NewSpecEdits creates a SpecModifier that defines the required OCI spec edits (as CDI ContainerEdits) from the specified
 // discoverer.
-func NewSpecEdits(logger logger.Interface, d discover.Discover) (oci.SpecModifier, error) {
+func NewSpecEdits(logger logger.Interface, d discover.Discover, opts ...Option) (oci.SpecModifier, error) {
 	c, err := FromDiscoverer(d)
 	if err != nil {
 		return nil, fmt.Errorf("error constructing container edits: %v", err)
@@ -27,6 +47,9 @@ func NewSpecEdits(logger logger.Interface, d discover.Discover) (oci.SpecModifie
 		ContainerEdits: *c,
 		logger:         logger,
 	}
+	for _, opt := range opts {
+		opt(&e)
+	}
 
 	return &e, nil
 }
@@ -82,18 +105,26 @@ func (e *edits) Modify(spec *ociSpecs.Spec) error {
 		return nil
 	}
 
+	mounts, err := e.resolveMountConflicts(spec, e.Mounts)
+	if err != nil {
+		return err
+	}
+	resolved := *e.ContainerEdits.ContainerEdits
+	resolved.Mounts = mounts
+
 	e.logger.Info("Mounts:")
-	for _, mount := range e.Mounts {
+	for _, mount := range resolved.Mounts {
 		e.logger.Infof("Mounting %v at %v", mount.HostPath, mount.ContainerPath)
 	}
 	e.logger.Infof("Devices:")
-	for _, device := range e.DeviceNodes {
+	for _, device := range resolved.DeviceNodes {
 		e.logger.Infof("Injecting %v", device.Path)
 	}
 	e.logger.Infof("Hooks:")
-	for _, hook := range e.Hooks {
+	for _, hook := range resolved.Hooks {
 		e.logger.Infof("Injecting %v %v", hook.Path, hook.Args)
 	}
 
-	return e.Apply(spec)
+	c := cdi.ContainerEdits{ContainerEdits: &resolved}
+	return c.Apply(spec)
 }
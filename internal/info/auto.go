@@ -1,36 +1,188 @@
 package info
 
 import (
+	"fmt"
+	"strings"
+
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup"
+)
+
+const (
+	containerCLIExecutable = "xdxct-container-cli"
+
+	// ModeAnnotation is the annotation that allows for the mode to be selected per container
+	// when the mode is set to "auto".
+	ModeAnnotation = "xdxct.com/runtime-mode"
 )
 
 type resolver struct {
 	logger logger.Interface
-	// info   infoInterface
+
+	annotations           map[string]string
+	cdiAnnotationPrefixes []string
+	specDirs              []string
+	defaultKind           string
+
+	hasContainerCLI func() bool
+}
+
+// Option is a function that configures the mode resolver.
+type Option func(*resolver)
+
+// WithAnnotations sets the annotations of the container for which the mode is resolved.
+func WithAnnotations(annotations map[string]string) Option {
+	return func(r *resolver) {
+		r.annotations = annotations
+	}
+}
+
+// WithCDIAnnotationPrefixes sets the prefixes of the annotations used by container engines to request CDI devices.
+func WithCDIAnnotationPrefixes(prefixes ...string) Option {
+	return func(r *resolver) {
+		r.cdiAnnotationPrefixes = prefixes
+	}
+}
+
+// WithSpecDirs sets the directories that are searched for CDI specs matching the requested devices.
+func WithSpecDirs(dirs ...string) Option {
+	return func(r *resolver) {
+		r.specDirs = dirs
+	}
+}
+
+// WithDefaultKind sets the kind used to construct CDI device names for devices that are not fully qualified.
+func WithDefaultKind(kind string) Option {
+	return func(r *resolver) {
+		r.defaultKind = kind
+	}
 }
 
 // ResolveAutoMode determines the correct mode for the platform if set to "auto"
-func ResolveAutoMode(logger logger.Interface, mode string, image image.GPU) (rmode string) {
+func ResolveAutoMode(logger logger.Interface, mode string, image image.GPU, opts ...Option) (rmode string) {
 	r := resolver{
-		logger: logger,
-		// info:   info,
+		logger:      logger,
+		defaultKind: "xdxct.com/gpu",
+	}
+	r.hasContainerCLI = r.locateContainerCLI
+	for _, opt := range opts {
+		opt(&r)
 	}
 	return r.resolveMode(mode, image)
 }
 
-// ResolveAutoMode determines the correct mode for the platform if set to "auto"
+// resolveMode determines the correct mode if set to "auto". The following rules are applied in order:
+//  1. The mode requested by the container through the mode annotation.
+//  2. cdi if CDI devices are requested through annotations by the container engine.
+//  3. cdi if only fully-qualified CDI devices are requested.
+//  4. legacy if the xdxct-container-cli is installed.
+//  5. cdi if CDI specs for all requested devices exist in the spec dirs.
+//  6. jit-cdi otherwise.
+//
+// The decision and the reason for it are logged at info level.
 func (r resolver) resolveMode(mode string, image image.GPU) (rmode string) {
 	if mode != "auto" {
 		return mode
 	}
+	var reason string
 	defer func() {
-		r.logger.Infof("Auto-detected mode as '%v'", rmode)
+		r.logger.Infof("Auto-detected mode as '%v': %v", rmode, reason)
 	}()
 
+	if requested, ok := r.annotations[ModeAnnotation]; ok {
+		switch requested {
+		case "legacy", "cdi", "jit-cdi":
+			reason = fmt.Sprintf("requested by annotation %v", ModeAnnotation)
+			return requested
+		}
+		r.logger.Warningf("Ignoring unsupported mode %q requested by annotation %v", requested, ModeAnnotation)
+	}
+
+	if key := r.getCDIAnnotation(); key != "" {
+		reason = fmt.Sprintf("CDI devices requested by annotation %v", key)
+		return "cdi"
+	}
+
 	if image.OnlyFullyQualifiedCDIDevices() {
+		reason = "only fully-qualified CDI devices requested"
+		return "cdi"
+	}
+
+	if r.hasContainerCLI() {
+		reason = fmt.Sprintf("%v is installed", containerCLIExecutable)
+		return "legacy"
+	}
+
+	if devices := r.getRequestedCDIDevices(image); len(devices) > 0 && r.hasCDISpecs(devices) {
+		reason = fmt.Sprintf("%v is not installed and CDI specs for %v exist in %v", containerCLIExecutable, devices, r.specDirs)
 		return "cdi"
 	}
 
-	return "legacy"
+	reason = fmt.Sprintf("%v is not installed and no matching CDI specs exist", containerCLIExecutable)
+	return "jit-cdi"
+}
+
+// getCDIAnnotation returns the key of an annotation with which the container engine requests CDI devices.
+func (r resolver) getCDIAnnotation() string {
+	for key := range r.annotations {
+		for _, prefix := range r.cdiAnnotationPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// getRequestedCDIDevices returns the CDI device names for the devices requested in the image.
+func (r resolver) getRequestedCDIDevices(image image.GPU) []string {
+	var devices []string
+	for _, id := range image.DevicesFromEnvvars("XDXCT_VISIBLE_DEVICES").List() {
+		if id == "" {
+			continue
+		}
+		if !parser.IsQualifiedName(id) {
+			id = r.defaultKind + "=" + id
+		}
+		devices = append(devices, id)
+	}
+	return devices
+}
+
+// hasCDISpecs checks whether CDI specs for all the specified devices exist in the spec dirs.
+func (r resolver) hasCDISpecs(devices []string) bool {
+	if len(r.specDirs) == 0 {
+		return false
+	}
+	cache, err := cdi.NewCache(
+		cdi.WithSpecDirs(r.specDirs...),
+		cdi.WithAutoRefresh(false),
+	)
+	if err != nil {
+		r.logger.Debugf("Errors loading CDI specs: %v", err)
+	}
+	if cache == nil {
+		return false
+	}
+	for _, device := range devices {
+		if cache.GetDevice(device) == nil {
+			r.logger.Debugf("No CDI spec found for %v", device)
+			return false
+		}
+	}
+	return true
+}
+
+// locateContainerCLI checks whether the xdxct-container-cli required for the legacy mode is installed.
+func (r resolver) locateContainerCLI() bool {
+	locator := lookup.NewExecutableLocator(r.logger, "/")
+	if _, err := locator.Locate(containerCLIExecutable); err != nil {
+		r.logger.Debugf("Could not locate %v: %v", containerCLIExecutable, err)
+		return false
+	}
+	return true
 }
@@ -0,0 +1,108 @@
+package info
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+)
+
+const testCDISpec = `cdiVersion: 0.5.0
+kind: xdxct.com/gpu
+devices:
+- name: "0"
+  containerEdits:
+    deviceNodes:
+    - path: /dev/dri/card0
+containerEdits: {}
+`
+
+func TestResolveAutoMode(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	specDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(specDir, "xdxct.yaml"), []byte(testCDISpec), 0644))
+
+	testCases := []struct {
+		description     string
+		mode            string
+		env             []string
+		annotations     map[string]string
+		hasContainerCLI bool
+		expectedMode    string
+	}{
+		{
+			description:  "non-auto mode is returned",
+			mode:         "legacy",
+			expectedMode: "legacy",
+		},
+		{
+			description:     "annotation overrides mode",
+			mode:            "auto",
+			annotations:     map[string]string{ModeAnnotation: "jit-cdi"},
+			hasContainerCLI: true,
+			expectedMode:    "jit-cdi",
+		},
+		{
+			description:     "invalid annotation is ignored",
+			mode:            "auto",
+			annotations:     map[string]string{ModeAnnotation: "other"},
+			hasContainerCLI: true,
+			expectedMode:    "legacy",
+		},
+		{
+			description:     "cdi annotations select cdi",
+			mode:            "auto",
+			annotations:     map[string]string{"cdi.k8s.io/gpu": "xdxct.com/gpu=0"},
+			hasContainerCLI: true,
+			expectedMode:    "cdi",
+		},
+		{
+			description:     "fully-qualified devices select cdi",
+			mode:            "auto",
+			env:             []string{"XDXCT_VISIBLE_DEVICES=xdxct.com/gpu=0"},
+			hasContainerCLI: true,
+			expectedMode:    "cdi",
+		},
+		{
+			description:     "container cli selects legacy",
+			mode:            "auto",
+			env:             []string{"XDXCT_VISIBLE_DEVICES=0"},
+			hasContainerCLI: true,
+			expectedMode:    "legacy",
+		},
+		{
+			description:  "matching cdi specs select cdi",
+			mode:         "auto",
+			env:          []string{"XDXCT_VISIBLE_DEVICES=0"},
+			expectedMode: "cdi",
+		},
+		{
+			description:  "missing cdi specs select jit-cdi",
+			mode:         "auto",
+			env:          []string{"XDXCT_VISIBLE_DEVICES=0,1"},
+			expectedMode: "jit-cdi",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			gpuImage, err := image.New(image.WithEnv(tc.env))
+			require.NoError(t, err)
+
+			r := resolver{
+				logger:                logger,
+				annotations:           tc.annotations,
+				cdiAnnotationPrefixes: []string{"cdi.k8s.io/"},
+				specDirs:              []string{specDir},
+				defaultKind:           "xdxct.com/gpu",
+				hasContainerCLI:       func() bool { return tc.hasContainerCLI },
+			}
+			require.Equal(t, tc.expectedMode, r.resolveMode(tc.mode, gpuImage))
+		})
+	}
+}
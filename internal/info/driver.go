@@ -0,0 +1,22 @@
+package info
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	driverVersionFile = "/sys/module/xdxgpu/version"
+)
+
+// GetDriverVersion returns the version of the loaded xdxgpu kernel module.
+// The specified root is prepended to the sysfs path that is queried.
+func GetDriverVersion(root string) (string, error) {
+	contents, err := os.ReadFile(filepath.Join(root, driverVersionFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read driver version: %v", err)
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
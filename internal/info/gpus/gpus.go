@@ -0,0 +1,69 @@
+package gpus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/device"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/xdxml"
+)
+
+type lister struct {
+	xdxmllib xdxml.Interface
+}
+
+var _ image.DeviceLister = (*lister)(nil)
+
+// NewDeviceLister creates a lister that queries the available devices using XDXML.
+// If no XDXML library is specified, the default library is used.
+func NewDeviceLister(xdxmllib xdxml.Interface) image.DeviceLister {
+	if xdxmllib == nil {
+		xdxmllib = xdxml.New()
+	}
+	return &lister{
+		xdxmllib: xdxmllib,
+	}
+}
+
+// ListDevices returns the devices available on the system.
+func (l *lister) ListDevices() ([]image.Device, error) {
+	if r := l.xdxmllib.Init(); r != xdxml.SUCCESS {
+		return nil, fmt.Errorf("failed to initialize XDXML: %v", r)
+	}
+	defer l.xdxmllib.Shutdown()
+
+	var devices []image.Device
+	err := device.New(device.WithXdxml(l.xdxmllib)).VisitDevices(func(i int, d device.Device) error {
+		uuid, ret := d.GetUUID()
+		if ret != xdxml.SUCCESS {
+			return fmt.Errorf("error getting UUID for device %d: %v", i, ret)
+		}
+		arch, ret := d.GetArchitecture()
+		if ret != xdxml.SUCCESS {
+			return fmt.Errorf("error getting architecture for device %d: %v", i, ret)
+		}
+		pciInfo, ret := d.GetPciInfo()
+		if ret != xdxml.SUCCESS {
+			return fmt.Errorf("error getting PCI info for device %d: %v", i, ret)
+		}
+
+		devices = append(devices, image.Device{
+			Index:    i,
+			UUID:     strings.TrimRight(uuid, "\x00"),
+			Arch:     strings.TrimRight(arch, "\x00"),
+			PCIBusID: GetBusID(pciInfo),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// GetBusID returns the sysfs representation of the PCI bus ID of a device.
+func GetBusID(p xdxml.PciInfo) string {
+	return fmt.Sprintf("%04x:%02x:%02x.%x", p.Domain, p.Bus, p.Device, p.Func)
+}
@@ -0,0 +1,230 @@
+package inject
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+// The following eBPF instruction encodings are used to construct device programs.
+const (
+	bpfLdxMemW  = 0x61 // BPF_LDX | BPF_MEM | BPF_W
+	bpfAlu64And = 0x57 // BPF_ALU64 | BPF_AND | BPF_K
+	bpfAlu64Mov = 0xb7 // BPF_ALU64 | BPF_MOV | BPF_K
+	bpfJmpJne   = 0x55 // BPF_JMP | BPF_JNE | BPF_K
+	bpfJmpExit  = 0x95 // BPF_JMP | BPF_EXIT
+)
+
+// The following device types are defined for struct bpf_cgroup_dev_ctx.
+const (
+	bpfDevcgDevBlock = 1
+	bpfDevcgDevChar  = 2
+)
+
+// bpfInsn matches struct bpf_insn. The destination register is stored in the lower and the
+// source register in the upper four bits of regs.
+type bpfInsn struct {
+	code uint8
+	regs uint8
+	off  int16
+	imm  int32
+}
+
+func insn(code uint8, dst uint8, src uint8, off int16, imm int32) bpfInsn {
+	return bpfInsn{code: code, regs: src<<4 | dst, off: off, imm: imm}
+}
+
+// deviceAllowInstructions returns the instructions that allow access to the specified device
+// nodes. The instructions return 1 for a matching device and otherwise fall through to the
+// instructions that follow them. The context in R1 is not modified so that these can be
+// prepended to an existing device program.
+func deviceAllowInstructions(devices []specs.LinuxDevice) []bpfInsn {
+	insns := []bpfInsn{
+		// R2 = ctx->access_type & 0xffff (the device type), R4 = ctx->major, R5 = ctx->minor
+		insn(bpfLdxMemW, 2, 1, 0, 0),
+		insn(bpfAlu64And, 2, 0, 0, 0xffff),
+		insn(bpfLdxMemW, 4, 1, 4, 0),
+		insn(bpfLdxMemW, 5, 1, 8, 0),
+	}
+	for _, d := range devices {
+		var devType int32
+		switch d.Type {
+		case "c", "u":
+			devType = bpfDevcgDevChar
+		case "b":
+			devType = bpfDevcgDevBlock
+		default:
+			continue
+		}
+		// Each comparison skips the remainder of the block for this device if it does not match.
+		insns = append(insns,
+			insn(bpfJmpJne, 2, 0, 4, devType),
+			insn(bpfJmpJne, 4, 0, 3, int32(d.Major)),
+			insn(bpfJmpJne, 5, 0, 2, int32(d.Minor)),
+			insn(bpfAlu64Mov, 0, 0, 0, 1),
+			insn(bpfJmpExit, 0, 0, 0, 0),
+		)
+	}
+	return insns
+}
+
+// bpf invokes the bpf syscall. Pointers in the attributes are stored as unsafe.Pointer so that
+// these remain valid; the architectures supported by the toolkit use 64-bit pointers which
+// match the __aligned_u64 fields of union bpf_attr.
+func bpf(cmd int, attr unsafe.Pointer, size uintptr) (int, error) {
+	r, _, errno := unix.Syscall(unix.SYS_BPF, uintptr(cmd), uintptr(attr), size)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(r), nil
+}
+
+// queryDevicePrograms returns the IDs of the device programs for the cgroup with the specified
+// file descriptor together with the flags that these were attached with. If effective is set,
+// the programs attached to the ancestors of the cgroup are included.
+func queryDevicePrograms(fd int, effective bool) ([]uint32, uint32, error) {
+	// attr matches the BPF_PROG_QUERY variant of union bpf_attr.
+	attr := struct {
+		targetFd    uint32
+		attachType  uint32
+		queryFlags  uint32
+		attachFlags uint32
+		progIds     unsafe.Pointer
+		progCount   uint32
+		_           uint32
+	}{
+		targetFd:   uint32(fd),
+		attachType: unix.BPF_CGROUP_DEVICE,
+	}
+	if effective {
+		attr.queryFlags = unix.BPF_F_QUERY_EFFECTIVE
+	}
+	if _, err := bpf(unix.BPF_PROG_QUERY, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return nil, 0, err
+	}
+	if attr.progCount == 0 {
+		return nil, attr.attachFlags, nil
+	}
+
+	ids := make([]uint32, attr.progCount)
+	attr.progIds = unsafe.Pointer(&ids[0])
+	if _, err := bpf(unix.BPF_PROG_QUERY, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return nil, 0, err
+	}
+	return ids[:attr.progCount], attr.attachFlags, nil
+}
+
+// getProgramByID returns a file descriptor for the eBPF program with the specified ID.
+func getProgramByID(id uint32) (int, error) {
+	attr := struct {
+		progID    uint32
+		nextID    uint32
+		openFlags uint32
+	}{
+		progID: id,
+	}
+	return bpf(unix.BPF_PROG_GET_FD_BY_ID, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+}
+
+// bpfProgInfo matches the leading fields of struct bpf_prog_info.
+type bpfProgInfo struct {
+	progType        uint32
+	id              uint32
+	tag             [8]byte
+	jitedProgLen    uint32
+	xlatedProgLen   uint32
+	jitedProgInsns  uint64
+	xlatedProgInsns unsafe.Pointer
+}
+
+// getProgramInstructions returns the instructions of the eBPF program with the specified file
+// descriptor as translated by the kernel.
+func getProgramInstructions(fd int) ([]bpfInsn, error) {
+	var info bpfProgInfo
+	attr := struct {
+		bpfFd   uint32
+		infoLen uint32
+		info    unsafe.Pointer
+	}{
+		bpfFd:   uint32(fd),
+		infoLen: uint32(unsafe.Sizeof(info)),
+		info:    unsafe.Pointer(&info),
+	}
+	if _, err := bpf(unix.BPF_OBJ_GET_INFO_BY_FD, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return nil, err
+	}
+	if info.xlatedProgLen == 0 {
+		return nil, fmt.Errorf("program instructions are not available")
+	}
+
+	insns := make([]bpfInsn, info.xlatedProgLen/uint32(unsafe.Sizeof(bpfInsn{})))
+	info = bpfProgInfo{
+		xlatedProgLen:   info.xlatedProgLen,
+		xlatedProgInsns: unsafe.Pointer(&insns[0]),
+	}
+	if _, err := bpf(unix.BPF_OBJ_GET_INFO_BY_FD, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return nil, err
+	}
+	if info.xlatedProgInsns == nil {
+		return nil, fmt.Errorf("program instructions are not available")
+	}
+	return insns, nil
+}
+
+// loadDeviceProgram loads the specified instructions as a cgroup device program and returns
+// a file descriptor for the program.
+func loadDeviceProgram(insns []bpfInsn) (int, error) {
+	license := []byte("Apache\x00")
+	// attr matches the BPF_PROG_LOAD variant of union bpf_attr.
+	attr := struct {
+		progType    uint32
+		insnCount   uint32
+		insns       unsafe.Pointer
+		license     unsafe.Pointer
+		logLevel    uint32
+		logSize     uint32
+		logBuf      unsafe.Pointer
+		kernVersion uint32
+		progFlags   uint32
+	}{
+		progType:  unix.BPF_PROG_TYPE_CGROUP_DEVICE,
+		insnCount: uint32(len(insns)),
+		insns:     unsafe.Pointer(&insns[0]),
+		license:   unsafe.Pointer(&license[0]),
+	}
+	return bpf(unix.BPF_PROG_LOAD, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+}
+
+// bpfAttachAttr matches the BPF_PROG_ATTACH and BPF_PROG_DETACH variants of union bpf_attr.
+type bpfAttachAttr struct {
+	targetFd     uint32
+	attachBpfFd  uint32
+	attachType   uint32
+	attachFlags  uint32
+	replaceBpfFd uint32
+}
+
+// attachDeviceProgram attaches the device program to the cgroup with the specified flags.
+func attachDeviceProgram(cgroupFd int, progFd int, flags uint32) error {
+	attr := bpfAttachAttr{
+		targetFd:    uint32(cgroupFd),
+		attachBpfFd: uint32(progFd),
+		attachType:  unix.BPF_CGROUP_DEVICE,
+		attachFlags: flags,
+	}
+	_, err := bpf(unix.BPF_PROG_ATTACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
+// detachDeviceProgram detaches the device program from the cgroup.
+func detachDeviceProgram(cgroupFd int, progFd int) error {
+	attr := bpfAttachAttr{
+		targetFd:    uint32(cgroupFd),
+		attachBpfFd: uint32(progFd),
+		attachType:  unix.BPF_CGROUP_DEVICE,
+	}
+	_, err := bpf(unix.BPF_PROG_DETACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
@@ -0,0 +1,176 @@
+package inject
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// allowDevices grants the container access to the specified device nodes through the devices
+// cgroup controller. On cgroup v2 hosts, device access is controlled by the eBPF programs
+// attached to the cgroup of the container; these are replaced by programs that also allow
+// access to the device nodes.
+func (i *Injector) allowDevices(devices []specs.LinuxDevice) error {
+	if len(devices) == 0 {
+		return nil
+	}
+
+	var st unix.Statfs_t
+	if err := unix.Statfs(cgroupRoot, &st); err != nil {
+		return fmt.Errorf("failed to determine cgroup version: %v", err)
+	}
+	if st.Type == unix.CGROUP2_SUPER_MAGIC {
+		cgroupPath, err := getCgroupPath(i.pid, "")
+		if err != nil {
+			return err
+		}
+		return i.allowDevicesV2(filepath.Join(cgroupRoot, cgroupPath), devices)
+	}
+
+	cgroupPath, err := getCgroupPath(i.pid, "devices")
+	if err != nil {
+		return err
+	}
+	allowFile := filepath.Join(cgroupRoot, "devices", cgroupPath, "devices.allow")
+
+	for _, d := range devices {
+		rule := fmt.Sprintf("%s %d:%d rwm", d.Type, d.Major, d.Minor)
+		i.logger.Debugf("Allowing device access %q in %v", rule, allowFile)
+		if err := os.WriteFile(allowFile, []byte(rule), 0); err != nil {
+			return fmt.Errorf("failed to allow access to %v: %v", d.Path, err)
+		}
+	}
+	return nil
+}
+
+// allowDevicesV2 grants access to the specified device nodes for the cgroup v2 cgroup at the
+// specified path. Each device program attached to the cgroup is replaced by a program that
+// allows access to the device nodes and otherwise applies the rules of the existing program.
+// Device programs attached to ancestors of the cgroup cannot be extended and an error is
+// returned if these restrict device access.
+func (i *Injector) allowDevicesV2(cgroupDir string, devices []specs.LinuxDevice) error {
+	dir, err := os.Open(cgroupDir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	cgroupFd := int(dir.Fd())
+
+	ids, attachFlags, err := queryDevicePrograms(cgroupFd, false)
+	if err != nil {
+		return fmt.Errorf("failed to query device programs for cgroup %v: %v", cgroupDir, err)
+	}
+	if len(ids) == 0 {
+		effective, _, err := queryDevicePrograms(cgroupFd, true)
+		if err != nil {
+			return fmt.Errorf("failed to query device programs for cgroup %v: %v", cgroupDir, err)
+		}
+		if len(effective) > 0 {
+			return fmt.Errorf("device access for cgroup %v is restricted by %d eBPF program(s) attached to its ancestors", cgroupDir, len(effective))
+		}
+		i.logger.Debugf("Device access for cgroup %v is not restricted", cgroupDir)
+		return nil
+	}
+
+	allow := deviceAllowInstructions(devices)
+	for _, id := range ids {
+		if err := i.extendDeviceProgram(cgroupFd, id, attachFlags, allow); err != nil {
+			return fmt.Errorf("failed to update device program %d for cgroup %v: %v", id, cgroupDir, err)
+		}
+	}
+	return nil
+}
+
+// extendDeviceProgram replaces the device program with the specified ID by a program that runs
+// the specified instructions before the instructions of the existing program.
+func (i *Injector) extendDeviceProgram(cgroupFd int, id uint32, attachFlags uint32, allow []bpfInsn) error {
+	progFd, err := getProgramByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to get program: %v", err)
+	}
+	defer unix.Close(progFd)
+
+	existing, err := getProgramInstructions(progFd)
+	if err != nil {
+		return fmt.Errorf("failed to get program instructions: %v", err)
+	}
+
+	insns := append(append([]bpfInsn{}, allow...), existing...)
+	newFd, err := loadDeviceProgram(insns)
+	if err != nil {
+		return fmt.Errorf("failed to load program: %v", err)
+	}
+	defer unix.Close(newFd)
+
+	i.logger.Debugf("Replacing device program %d with a program of %d instructions", id, len(insns))
+	// If multiple programs are allowed, the new program is attached before the existing one is
+	// detached so that the existing rules remain in effect throughout. Otherwise attaching the
+	// new program replaces the existing one.
+	if err := attachDeviceProgram(cgroupFd, newFd, attachFlags); err != nil {
+		return fmt.Errorf("failed to attach program: %v", err)
+	}
+	if attachFlags&unix.BPF_F_ALLOW_MULTI == 0 {
+		return nil
+	}
+	if err := detachDeviceProgram(cgroupFd, progFd); err != nil {
+		return fmt.Errorf("failed to detach program: %v", err)
+	}
+	return nil
+}
+
+// getCgroupPath returns the path of the cgroup of the specified process for the specified
+// controller. An empty controller selects the cgroup v2 unified hierarchy.
+func getCgroupPath(pid int, controller string) (string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	path, err := parseCgroupPath(f, controller)
+	if err != nil {
+		return "", fmt.Errorf("process %d: %v", pid, err)
+	}
+	return path, nil
+}
+
+// parseCgroupPath returns the cgroup path for the specified controller from the contents of a
+// /proc/<pid>/cgroup file.
+func parseCgroupPath(r io.Reader, controller string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// Each line has the format hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if controller == "" {
+			if parts[0] == "0" && parts[1] == "" {
+				return parts[2], nil
+			}
+			continue
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			if c == controller {
+				return parts[2], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if controller == "" {
+		return "", fmt.Errorf("no unified cgroup found")
+	}
+	return "", fmt.Errorf("no %v cgroup found", controller)
+}
@@ -0,0 +1,151 @@
+package inject
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseCgroupPath(t *testing.T) {
+	const hybrid = `12:devices:/docker/abc
+11:cpu,cpuacct:/docker/abc
+0::/system.slice/docker-abc.scope
+`
+	const unified = `0::/system.slice/docker-def.scope
+`
+
+	testCases := []struct {
+		description   string
+		contents      string
+		controller    string
+		expected      string
+		expectedError bool
+	}{
+		{description: "v1 devices controller", contents: hybrid, controller: "devices", expected: "/docker/abc"},
+		{description: "v1 combined controllers", contents: hybrid, controller: "cpuacct", expected: "/docker/abc"},
+		{description: "hybrid unified hierarchy", contents: hybrid, expected: "/system.slice/docker-abc.scope"},
+		{description: "unified hierarchy", contents: unified, expected: "/system.slice/docker-def.scope"},
+		{description: "missing controller", contents: unified, controller: "devices", expectedError: true},
+		{description: "missing unified hierarchy", contents: "12:devices:/docker/abc\n", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			path, err := parseCgroupPath(strings.NewReader(tc.contents), tc.controller)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, path)
+		})
+	}
+}
+
+func TestDeviceAllowInstructions(t *testing.T) {
+	insns := deviceAllowInstructions([]specs.LinuxDevice{
+		{Path: "/dev/xdxgpu0", Type: "c", Major: 195, Minor: 0},
+		{Path: "/dev/fifo", Type: "p"},
+		{Path: "/dev/xdxblk", Type: "b", Major: 8, Minor: 1},
+	})
+	// The device fields are loaded once and each supported device adds a block of 5 instructions.
+	require.Len(t, insns, 4+2*5)
+	require.Equal(t, insn(bpfJmpJne, 2, 0, 4, bpfDevcgDevChar), insns[4])
+	require.Equal(t, insn(bpfJmpJne, 4, 0, 3, 195), insns[5])
+	require.Equal(t, insn(bpfJmpJne, 2, 0, 4, bpfDevcgDevBlock), insns[9])
+	require.Equal(t, insn(bpfJmpJne, 5, 0, 2, 1), insns[11])
+}
+
+func TestAllowDevicesV2(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	root := createTestCgroup(t)
+
+	// A program that denies access to all devices restricts the parent cgroup; the child
+	// cgroup is only restricted by the program attached to its ancestor.
+	denyAll := []bpfInsn{
+		insn(bpfAlu64Mov, 0, 0, 0, 0),
+		insn(bpfJmpExit, 0, 0, 0, 0),
+	}
+	progFd, err := loadDeviceProgram(denyAll)
+	if err != nil {
+		t.Skipf("cannot load device program: %v", err)
+	}
+	defer unix.Close(progFd)
+
+	dir, err := os.Open(root)
+	require.NoError(t, err)
+	defer dir.Close()
+	require.NoError(t, attachDeviceProgram(int(dir.Fd()), progFd, unix.BPF_F_ALLOW_MULTI))
+
+	child := filepath.Join(root, "child")
+	require.NoError(t, os.Mkdir(child, 0755))
+	defer os.Remove(child)
+
+	devNull := specs.LinuxDevice{Path: "/dev/null", Type: "c", Major: 1, Minor: 3}
+	injector := New(logger, 0, "")
+
+	require.False(t, canOpenInCgroup(t, root, "/dev/null"))
+	require.Error(t, injector.allowDevicesV2(child, []specs.LinuxDevice{devNull}))
+
+	require.NoError(t, injector.allowDevicesV2(root, []specs.LinuxDevice{devNull}))
+	require.True(t, canOpenInCgroup(t, root, "/dev/null"))
+	require.False(t, canOpenInCgroup(t, root, "/dev/zero"))
+
+	ids, _, err := queryDevicePrograms(int(dir.Fd()), false)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+}
+
+// createTestCgroup creates a cgroup in the cgroup v2 hierarchy that is removed when the test
+// completes. The test is skipped if no cgroup v2 hierarchy is available.
+func createTestCgroup(t *testing.T) string {
+	if os.Geteuid() != 0 {
+		t.Skip("test requires root")
+	}
+	f, err := os.Open("/proc/self/mountinfo")
+	require.NoError(t, err)
+	defer f.Close()
+
+	var mountpoint string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// The filesystem type follows the " - " separator and the mount point is the fifth field.
+		fields := strings.Fields(scanner.Text())
+		for i, field := range fields {
+			if field == "-" && i+1 < len(fields) && fields[i+1] == "cgroup2" {
+				mountpoint = fields[4]
+			}
+		}
+	}
+	if mountpoint == "" {
+		t.Skip("no cgroup v2 hierarchy found")
+	}
+
+	root := filepath.Join(mountpoint, "xdxct-inject-test")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Skipf("cannot create cgroup: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Remove(root)
+	})
+	return root
+}
+
+// canOpenInCgroup checks whether a process in the specified cgroup can open the specified path.
+func canOpenInCgroup(t *testing.T, cgroup string, path string) bool {
+	procs := filepath.Join(cgroup, "cgroup.procs")
+	err := exec.Command("sh", "-c", "echo $$ > "+procs+" && exec cat "+path).Run()
+	if _, ok := err.(*exec.ExitError); ok {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
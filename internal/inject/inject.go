@@ -0,0 +1,211 @@
+package inject
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+)
+
+const (
+	defaultHookTimeout = 30 * time.Second
+)
+
+// Injector applies spec edits to a container that has already been created. This is used
+// when the devices cannot be injected by modifying the OCI spec before the container is
+// created, for example when the runtime hook is invoked directly through the docker --gpus flag.
+type Injector struct {
+	logger logger.Interface
+	pid    int
+	rootfs string
+}
+
+// New creates an injector for the container with the specified init process and root filesystem.
+func New(logger logger.Interface, pid int, rootfs string) *Injector {
+	i := Injector{
+		logger: logger,
+		pid:    pid,
+		rootfs: rootfs,
+	}
+	return &i
+}
+
+// Inject applies the mounts, device nodes, and hooks defined in the specified edits to the
+// container. The edits are represented as an OCI spec that only contains the modifications.
+// The mounts and device nodes are created in the mount namespace of the container, device
+// access is granted through the devices cgroup, and the hooks are run with the specified
+// container state as input.
+func (i *Injector) Inject(edits *specs.Spec, state []byte) error {
+	if edits.Process != nil && len(edits.Process.Env) > 0 {
+		i.logger.Warningf("Ignoring environment variables for running container: %v", edits.Process.Env)
+	}
+
+	var devices []specs.LinuxDevice
+	if edits.Linux != nil {
+		devices = edits.Linux.Devices
+	}
+	if err := i.allowDevices(devices); err != nil {
+		return fmt.Errorf("failed to allow device access: %v", err)
+	}
+
+	return inMountNamespace(i.pid, func() error {
+		for _, m := range edits.Mounts {
+			if err := i.mount(m); err != nil {
+				return fmt.Errorf("failed to mount %v: %v", m.Source, err)
+			}
+		}
+		for _, d := range devices {
+			if err := i.createDevice(d); err != nil {
+				return fmt.Errorf("failed to create device node %v: %v", d.Path, err)
+			}
+		}
+		if edits.Hooks == nil {
+			return nil
+		}
+		var hooks []specs.Hook
+		hooks = append(hooks, edits.Hooks.Prestart...)
+		hooks = append(hooks, edits.Hooks.CreateRuntime...)
+		hooks = append(hooks, edits.Hooks.CreateContainer...)
+		for _, h := range hooks {
+			if err := i.runHook(h, state); err != nil {
+				return fmt.Errorf("failed to run hook %v: %v", h.Args, err)
+			}
+		}
+		return nil
+	})
+}
+
+// mount bind mounts the source of the specified mount into the container.
+func (i *Injector) mount(m specs.Mount) error {
+	target, err := securejoin.Resolve(i.rootfs, m.Destination)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %v in container: %v", m.Destination, err)
+	}
+
+	info, err := os.Stat(m.Source)
+	if err != nil {
+		return err
+	}
+	if err := createMountpoint(target, info.IsDir()); err != nil {
+		return err
+	}
+
+	i.logger.Infof("Mounting %v at %v", m.Source, target)
+	if err := unix.Mount(m.Source, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return err
+	}
+
+	flags := uintptr(0)
+	for _, o := range m.Options {
+		switch o {
+		case "ro":
+			flags |= unix.MS_RDONLY
+		case "nosuid":
+			flags |= unix.MS_NOSUID
+		case "nodev":
+			flags |= unix.MS_NODEV
+		case "noexec":
+			flags |= unix.MS_NOEXEC
+		}
+	}
+	if flags == 0 {
+		return nil
+	}
+	return unix.Mount("", target, "", unix.MS_BIND|unix.MS_REMOUNT|flags, "")
+}
+
+// createDevice creates the specified device node in the container. If the node cannot be
+// created, for example in a user namespace, the device node on the host is bind mounted instead.
+func (i *Injector) createDevice(d specs.LinuxDevice) error {
+	target, err := securejoin.Resolve(i.rootfs, d.Path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %v in container: %v", d.Path, err)
+	}
+	if _, err := os.Lstat(target); err == nil {
+		i.logger.Debugf("Device node %v already exists", target)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	var mode uint32
+	switch d.Type {
+	case "c", "u":
+		mode = unix.S_IFCHR
+	case "b":
+		mode = unix.S_IFBLK
+	default:
+		return fmt.Errorf("unsupported device type %q", d.Type)
+	}
+	perm := os.FileMode(0666)
+	if d.FileMode != nil {
+		perm = d.FileMode.Perm()
+	}
+
+	i.logger.Infof("Creating device node %v", target)
+	err = unix.Mknod(target, mode|uint32(perm), int(unix.Mkdev(uint32(d.Major), uint32(d.Minor))))
+	if err != nil {
+		i.logger.Debugf("Failed to create device node %v: %v; using bind mount", target, err)
+		return i.mount(specs.Mount{Source: d.Path, Destination: d.Path})
+	}
+	if err := os.Chmod(target, perm); err != nil {
+		return err
+	}
+	if d.UID != nil || d.GID != nil {
+		uid, gid := -1, -1
+		if d.UID != nil {
+			uid = int(*d.UID)
+		}
+		if d.GID != nil {
+			gid = int(*d.GID)
+		}
+		return os.Lchown(target, uid, gid)
+	}
+	return nil
+}
+
+// runHook runs the specified hook with the container state as input.
+func (i *Injector) runHook(h specs.Hook, state []byte) error {
+	timeout := defaultHookTimeout
+	if h.Timeout != nil {
+		timeout = time.Duration(*h.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Path)
+	if len(h.Args) > 0 {
+		cmd.Args = h.Args
+	}
+	cmd.Env = h.Env
+	cmd.Stdin = bytes.NewReader(state)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		i.logger.Debugf("Output from hook %v: %s", h.Args, output)
+	}
+	return err
+}
+
+func createMountpoint(target string, isDir bool) error {
+	if isDir {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
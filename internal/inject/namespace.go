@@ -0,0 +1,39 @@
+package inject
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// inMountNamespace runs the specified function in the mount namespace of the process with
+// the specified pid. Processes started from the function also run in this namespace.
+func inMountNamespace(pid int, f func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread is intentionally not unlocked. This ensures that it is terminated when the
+		// goroutine exits instead of being reused while in the container's mount namespace.
+		runtime.LockOSThread()
+		errCh <- func() error {
+			// Joining a mount namespace requires that filesystem attributes are not shared
+			// with the other threads of the process.
+			if err := unix.Unshare(unix.CLONE_FS); err != nil {
+				return fmt.Errorf("failed to unshare filesystem attributes: %v", err)
+			}
+
+			nsPath := fmt.Sprintf("/proc/%d/ns/mnt", pid)
+			fd, err := unix.Open(nsPath, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			if err != nil {
+				return fmt.Errorf("failed to open %v: %v", nsPath, err)
+			}
+			defer unix.Close(fd)
+
+			if err := unix.Setns(fd, unix.CLONE_NEWNS); err != nil {
+				return fmt.Errorf("failed to join mount namespace of process %d: %v", pid, err)
+			}
+			return f()
+		}()
+	}()
+	return <-errCh
+}
@@ -10,12 +10,12 @@ type empty struct {
 var _ LDCache = (*empty)(nil)
 
 // List always returns nil for an empty ldcache
-func (e *empty) List() ([]string, []string) {
-	return nil, nil
+func (e *empty) List() Libraries {
+	return nil
 }
 
 // Lookup logs a debug message and returns nil for an empty ldcache
-func (e *empty) Lookup(prefixes ...string) ([]string, []string) {
+func (e *empty) Lookup(prefixes ...string) Libraries {
 	e.logger.Debugf("Calling Lookup(%v) on empty ldcache: %v", prefixes, e.path)
-	return nil, nil
+	return nil
 }
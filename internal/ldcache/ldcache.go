@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"syscall"
 	"unsafe"
@@ -24,14 +25,34 @@ const (
 )
 
 const (
-	flagTypeMask = 0x00ff
-	flagTypeELF  = 0x0001
+	flagTypeMask     = 0x00ff
+	flagTypeELF      = 0x0001
+	flagTypeELFLibc6 = 0x0003
 
 	flagArchMask    = 0xff00
 	flagArchI386    = 0x0000
 	flagArchX8664   = 0x0300
 	flagArchX32     = 0x0800
 	flagArchPpc64le = 0x0500
+	flagArchAArch64 = 0x0a00
+	// The RISC-V flags encode the floating-point ABI of the library.
+	flagArchRiscvSoft   = 0x0f00
+	flagArchRiscvDouble = 0x1000
+)
+
+const (
+	// headerFlagLittleEndian marks a cache in the new format as being little endian.
+	headerFlagLittleEndian = 2
+)
+
+const (
+	// extensionMagic identifies the extension section of a cache in the new format.
+	extensionMagic = 0xeaa42174
+	// extensionTagHWCaps identifies the extension that lists the glibc-hwcaps subdirectories.
+	extensionTagHWCaps = 1
+	// hwcapExtension is set in the upper 32 bits of the hwcap of an entry for a library in a
+	// glibc-hwcaps subdirectory. The lower 32 bits are the index of the subdirectory.
+	hwcapExtension = uint64(1) << 62
 )
 
 var errInvalidCache = errors.New("invalid ld.so.cache file")
@@ -51,8 +72,11 @@ type header2 struct {
 	Version   [len(magicVersion)]byte
 	NLibs     uint32
 	TableSize uint32
-	_         [3]uint32 // unused
-	_         uint64    // force 8 byte alignment
+	Flags     uint8
+	_         [3]uint8
+	// ExtensionOffset is the offset of the (optional) extension section
+	ExtensionOffset uint32
+	_               [3]uint32 // unused
 }
 
 type entry2 struct {
@@ -62,10 +86,60 @@ type entry2 struct {
 	HWCap      uint64
 }
 
+type extensionHeader struct {
+	Magic uint32
+	Count uint32
+}
+
+type extensionSection struct {
+	Tag    uint32
+	Flags  uint32
+	Offset uint32
+	Size   uint32
+}
+
+// isHWCapExtension checks whether the specified hwcap refers to a glibc-hwcaps subdirectory.
+func isHWCapExtension(hwcap uint64) bool {
+	return hwcap>>32 == hwcapExtension>>32
+}
+
+// Arch identifies the architecture (and ABI) of the libraries in an LDCache
+type Arch string
+
+const (
+	ArchI386             = Arch("i386")
+	ArchX8664            = Arch("x86_64")
+	ArchX32              = Arch("x32")
+	ArchPpc64le          = Arch("ppc64le")
+	ArchAArch64          = Arch("aarch64")
+	ArchRiscv64          = Arch("riscv64")
+	ArchRiscv64SoftFloat = Arch("riscv64-soft-float")
+)
+
+// Libraries holds the paths to libraries grouped by architecture
+type Libraries map[Arch][]string
+
+// NativeArch returns the architecture of the libraries that can be loaded by the current process.
+func NativeArch() Arch {
+	switch runtime.GOARCH {
+	case "386":
+		return ArchI386
+	case "amd64":
+		return ArchX8664
+	case "arm64":
+		return ArchAArch64
+	case "ppc64le":
+		return ArchPpc64le
+	case "riscv64":
+		return ArchRiscv64
+	}
+	return Arch(runtime.GOARCH)
+}
+
 // LDCache represents the interface for performing lookups into the LDCache
 type LDCache interface {
-	List() ([]string, []string)
-	Lookup(...string) ([]string, []string)
+	List() Libraries
+	Lookup(...string) Libraries
 }
 
 type ldcache struct {
@@ -75,6 +149,13 @@ type ldcache struct {
 	header     header2
 	entries    []entry2
 
+	// libs1 and entries1 hold the legacy ld.so-1.7.0 section of the cache, if present.
+	libs1    []byte
+	entries1 []entry1
+
+	// hwcapsSubdirs are the glibc-hwcaps subdirectories listed in the extension section.
+	hwcapsSubdirs []string
+
 	root   string
 	logger logger.Interface
 }
@@ -143,13 +224,23 @@ func (c *ldcache) parse() error {
 		if err := binary.Read(c, binary.LittleEndian, &header); err != nil {
 			return err
 		}
-		n := int64(header.NLibs) * int64(unsafe.Sizeof(entry1{}))
-		offset, err := c.Seek(n, 1) // skip old entries
-		if err != nil {
+		if int64(header.NLibs)*int64(unsafe.Sizeof(entry1{})) > int64(c.Len()) {
+			return errInvalidCache
+		}
+		c.entries1 = make([]entry1, header.NLibs)
+		if err := binary.Read(c, binary.LittleEndian, &c.entries1); err != nil {
 			return err
 		}
-		n = (-offset) & int64(unsafe.Alignof(c.header)-1)
-		_, err = c.Seek(n, 1) // skip padding
+		// The string offsets of the old entries are relative to the end of the entries.
+		offset := c.Size() - int64(c.Len())
+		c.libs1 = c.data[offset:]
+
+		n := (-offset) & int64(unsafe.Alignof(entry2{})-1)
+		if offset+n+int64(len(magicString2)) > c.Size() || strn(c.data[offset+n:], len(magicString2)) != magicString2 {
+			// The cache contains only the old format.
+			return nil
+		}
+		_, err := c.Seek(n, 1) // skip padding
 		if err != nil {
 			return err
 		}
@@ -162,78 +253,152 @@ func (c *ldcache) parse() error {
 	if c.Magic() != magicString2 || c.Version() != magicVersion {
 		return errInvalidCache
 	}
+	if int64(c.header.NLibs)*int64(unsafe.Sizeof(entry2{})) > int64(c.Len()) {
+		return errInvalidCache
+	}
 	c.entries = make([]entry2, c.header.NLibs)
 	if err := binary.Read(c, binary.LittleEndian, &c.entries); err != nil {
 		return err
 	}
+	return c.parseExtension()
+}
+
+// parseExtension reads the glibc-hwcaps subdirectories from the extension section of the cache.
+// The offsets in the extension section are relative to the start of the new format header.
+func (c *ldcache) parseExtension() error {
+	offset := int64(c.header.ExtensionOffset)
+	if offset == 0 {
+		return nil
+	}
+	r := bytes.NewReader(c.libs)
+	if _, err := r.Seek(offset, 0); err != nil {
+		return err
+	}
+	var header extensionHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return fmt.Errorf("failed to read extension header: %v", err)
+	}
+	if header.Magic != extensionMagic {
+		return errInvalidCache
+	}
+	if int64(header.Count)*int64(unsafe.Sizeof(extensionSection{})) > int64(r.Len()) {
+		return errInvalidCache
+	}
+	sections := make([]extensionSection, header.Count)
+	if err := binary.Read(r, binary.LittleEndian, sections); err != nil {
+		return fmt.Errorf("failed to read extension sections: %v", err)
+	}
+
+	for _, s := range sections {
+		if s.Tag != extensionTagHWCaps {
+			continue
+		}
+		if s.Size%4 != 0 || int64(s.Offset)+int64(s.Size) > int64(len(c.libs)) {
+			return errInvalidCache
+		}
+		offsets := make([]uint32, s.Size/4)
+		if err := binary.Read(bytes.NewReader(c.libs[s.Offset:s.Offset+s.Size]), binary.LittleEndian, offsets); err != nil {
+			return err
+		}
+		for _, o := range offsets {
+			if o >= uint32(len(c.libs)) {
+				return errInvalidCache
+			}
+			c.hwcapsSubdirs = append(c.hwcapsSubdirs, bytesToString(c.libs[o:]))
+		}
+	}
 	return nil
 }
 
 type entry struct {
 	libname string
-	bits    int
+	arch    Arch
 	value   string
 }
 
+// getArch returns the architecture for the specified entry flags. Entries that are not ELF
+// libraries or have an unsupported architecture are not selected.
+func getArch(flags int32) (Arch, bool) {
+	if ((flags & flagTypeMask) & flagTypeELF) == 0 {
+		return "", false
+	}
+	switch flags & flagArchMask {
+	case flagArchI386:
+		return ArchI386, true
+	case flagArchX8664:
+		return ArchX8664, true
+	case flagArchX32:
+		return ArchX32, true
+	case flagArchPpc64le:
+		return ArchPpc64le, true
+	case flagArchAArch64:
+		return ArchAArch64, true
+	case flagArchRiscvDouble:
+		return ArchRiscv64, true
+	case flagArchRiscvSoft:
+		return ArchRiscv64SoftFloat, true
+	}
+	return "", false
+}
+
 // getEntries returns the entires of the ldcache in a go-friendly struct.
+// The entries of the new format are returned before those of the legacy format.
 func (c *ldcache) getEntries(selected func(string) bool) []entry {
 	var entries []entry
 	for _, e := range c.entries {
-		bits := 0
-		if ((e.Flags & flagTypeMask) & flagTypeELF) == 0 {
-			continue
-		}
-		switch e.Flags & flagArchMask {
-		case flagArchX8664:
-			fallthrough
-		case flagArchPpc64le:
-			bits = 64
-		case flagArchX32:
-			fallthrough
-		case flagArchI386:
-			bits = 32
-		default:
-			continue
-		}
-		if e.Key > uint32(len(c.libs)) || e.Value > uint32(len(c.libs)) {
-			continue
-		}
-		lib := bytesToString(c.libs[e.Key:])
-		if lib == "" {
-			c.logger.Debugf("Skipping invalid lib")
-			continue
-		}
-		if !selected(lib) {
-			continue
+		if e := c.getEntry(selected, c.libs, e.Flags, e.Key, e.Value); e != nil {
+			entries = append(entries, *e)
 		}
-		value := bytesToString(c.libs[e.Value:])
-		if value == "" {
-			c.logger.Debugf("Skipping invalid value for lib %v", lib)
-			continue
-		}
-		e := entry{
-			libname: lib,
-			bits:    bits,
-			value:   value,
+	}
+	for _, e := range c.entries1 {
+		if e := c.getEntry(selected, c.libs1, e.Flags, e.Key, e.Value); e != nil {
+			entries = append(entries, *e)
 		}
-
-		entries = append(entries, e)
 	}
-
 	return entries
 }
 
+// getEntry returns the entry for the specified flags and string offsets. Nil is returned if the
+// entry is invalid or not selected.
+func (c *ldcache) getEntry(selected func(string) bool, libs []byte, flags int32, key uint32, value uint32) *entry {
+	arch, ok := getArch(flags)
+	if !ok {
+		return nil
+	}
+	if key > uint32(len(libs)) || value > uint32(len(libs)) {
+		return nil
+	}
+	lib := bytesToString(libs[key:])
+	if lib == "" {
+		c.logger.Debugf("Skipping invalid lib")
+		return nil
+	}
+	if !selected(lib) {
+		return nil
+	}
+	path := bytesToString(libs[value:])
+	if path == "" {
+		c.logger.Debugf("Skipping invalid value for lib %v", lib)
+		return nil
+	}
+	return &entry{
+		libname: lib,
+		arch:    arch,
+		value:   path,
+	}
+}
+
 // List creates a list of libraires in the ldcache.
-// The 32-bit and 64-bit libraries are returned separately.
-func (c *ldcache) List() ([]string, []string) {
+// The libraries are grouped by architecture.
+func (c *ldcache) List() Libraries {
 	all := func(s string) bool { return true }
 
 	return c.resolveSelected(all)
 }
 
 // Lookup searches the ldcache for the specified prefixes.
-// The 32-bit and 64-bit libraries matching the prefixes are returned.
-func (c *ldcache) Lookup(libPrefixes ...string) ([]string, []string) {
+// The libraries matching the prefixes are returned grouped by architecture.
+func (c *ldcache) Lookup(libPrefixes ...string) Libraries {
 	c.logger.Debugf("Looking up %v in cache", libPrefixes)
 
 	// We define a functor to check whether a given library name matches any of the prefixes
@@ -250,9 +415,9 @@ func (c *ldcache) Lookup(libPrefixes ...string) ([]string, []string) {
 }
 
 // resolveSelected process the entries in the LDCach based on the supplied filter and returns the resolved paths.
-// The paths are separated by bittage.
-func (c *ldcache) resolveSelected(selected func(string) bool) ([]string, []string) {
-	paths := make(map[int][]string)
+// The paths are grouped by architecture.
+func (c *ldcache) resolveSelected(selected func(string) bool) Libraries {
+	paths := make(Libraries)
 	processed := make(map[string]bool)
 
 	for _, e := range c.getEntries(selected) {
@@ -264,11 +429,11 @@ func (c *ldcache) resolveSelected(selected func(string) bool) ([]string, []strin
 		if processed[path] {
 			continue
 		}
-		paths[e.bits] = append(paths[e.bits], path)
+		paths[e.arch] = append(paths[e.arch], path)
 		processed[path] = true
 	}
 
-	return paths[32], paths[64]
+	return paths
 }
 
 // resolve resolves the specified ldcache entry based on the value being processed.
@@ -0,0 +1,147 @@
+package ldcache
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupByArch(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	root := t.TempDir()
+
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/x86_64-linux-gnu/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_X86_64, "libxdxct.so.1")
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/i386-linux-gnu/libxdxct.so.1"), elf.ELFCLASS32, elf.EM_386, "libxdxct.so.1")
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/aarch64-linux-gnu/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_AARCH64, "libxdxct.so.1")
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/powerpc64le-linux-gnu/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_PPC64, "libxdxct.so.1")
+	writeSharedLibraryWithFlags(t, filepath.Join(root, "usr/lib/riscv64-linux-gnu/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_RISCV, riscvFloatABIDouble, "libxdxct.so.1")
+	writeSharedLibraryWithFlags(t, filepath.Join(root, "usr/lib/riscv64-soft/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_RISCV, riscvFloatABISoft, "libxdxct.so.1")
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/x86_64-linux-gnu/libother.so.1"), elf.ELFCLASS64, elf.EM_X86_64, "libother.so.1")
+
+	entries, err := Scan(logger, root,
+		"/usr/lib/x86_64-linux-gnu",
+		"/usr/lib/i386-linux-gnu",
+		"/usr/lib/aarch64-linux-gnu",
+		"/usr/lib/powerpc64le-linux-gnu",
+		"/usr/lib/riscv64-linux-gnu",
+		"/usr/lib/riscv64-soft",
+	)
+	require.NoError(t, err)
+	writeCache(t, root, entries)
+
+	c, err := New(logger, root)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		Libraries{
+			ArchX8664:            []string{filepath.Join(root, "usr/lib/x86_64-linux-gnu/libxdxct.so.1")},
+			ArchI386:             []string{filepath.Join(root, "usr/lib/i386-linux-gnu/libxdxct.so.1")},
+			ArchAArch64:          []string{filepath.Join(root, "usr/lib/aarch64-linux-gnu/libxdxct.so.1")},
+			ArchPpc64le:          []string{filepath.Join(root, "usr/lib/powerpc64le-linux-gnu/libxdxct.so.1")},
+			ArchRiscv64:          []string{filepath.Join(root, "usr/lib/riscv64-linux-gnu/libxdxct.so.1")},
+			ArchRiscv64SoftFloat: []string{filepath.Join(root, "usr/lib/riscv64-soft/libxdxct.so.1")},
+		},
+		c.Lookup("libxdxct.so"),
+	)
+	require.Len(t, c.List()[ArchX8664], 2)
+}
+
+func TestParseLegacySection(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	root := t.TempDir()
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib64/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_AARCH64, "libxdxct.so.1")
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/libxdxct.so.1"), elf.ELFCLASS32, elf.EM_386, "libxdxct.so.1")
+
+	legacy := []Entry{
+		{Soname: "libxdxct.so.1", Path: "/usr/lib/libxdxct.so.1", Flags: flagTypeELFLibc6 | flagArchI386},
+	}
+	current := []Entry{
+		{Soname: "libxdxct.so.1", Path: "/usr/lib64/libxdxct.so.1", Flags: flagTypeELFLibc6 | flagArchAArch64},
+	}
+
+	testCases := []struct {
+		description string
+		contents    []byte
+		expected    Libraries
+	}{
+		{
+			description: "legacy format only",
+			contents:    buildLegacyCache(t, legacy, nil),
+			expected: Libraries{
+				ArchI386: []string{filepath.Join(root, "usr/lib/libxdxct.so.1")},
+			},
+		},
+		{
+			description: "legacy and new format",
+			contents:    buildLegacyCache(t, legacy, current),
+			expected: Libraries{
+				ArchI386:    []string{filepath.Join(root, "usr/lib/libxdxct.so.1")},
+				ArchAArch64: []string{filepath.Join(root, "usr/lib64/libxdxct.so.1")},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(root, ldcachePath), tc.contents, 0644))
+
+			c, err := New(logger, root)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, c.Lookup("libxdxct"))
+		})
+	}
+}
+
+func writeCache(t *testing.T, root string, entries []Entry) {
+	var cache bytes.Buffer
+	require.NoError(t, Write(&cache, entries))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ldcachePath), cache.Bytes(), 0644))
+}
+
+// buildLegacyCache constructs a cache with a legacy ld.so-1.7.0 section containing the specified
+// entries. If current entries are specified, a section in the new format follows the legacy
+// section and the strings of the legacy entries follow its string table.
+func buildLegacyCache(t *testing.T, legacy []Entry, current []Entry) []byte {
+	var strs bytes.Buffer
+	if len(current) > 0 {
+		require.NoError(t, Write(&strs, current))
+	}
+
+	// The string offsets are relative to the end of the legacy entries and the new section is
+	// aligned to 8 bytes.
+	size := 16 + 12*len(legacy)
+	padding := (8 - size%8) % 8
+	if len(current) == 0 {
+		padding = 0
+	}
+	offset := func(s string) uint32 {
+		i := bytes.Index(strs.Bytes(), []byte(s+"\x00"))
+		if i < 0 {
+			i = strs.Len()
+			strs.WriteString(s + "\x00")
+		}
+		return uint32(padding + i)
+	}
+
+	var entries []entry1
+	for _, e := range legacy {
+		entries = append(entries, entry1{Flags: e.Flags, Key: offset(e.Soname), Value: offset(e.Path)})
+	}
+
+	var contents bytes.Buffer
+	header := header1{NLibs: uint32(len(entries))}
+	copy(header.Magic[:], magicString1)
+	require.NoError(t, binary.Write(&contents, binary.LittleEndian, &header))
+	require.NoError(t, binary.Write(&contents, binary.LittleEndian, entries))
+	contents.Write(make([]byte, padding))
+	contents.Write(strs.Bytes())
+	return contents.Bytes()
+}
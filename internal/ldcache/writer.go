@@ -0,0 +1,405 @@
+package ldcache
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/securejoin"
+)
+
+const (
+	// The offsets of the e_flags field in the ELF header for 32- and 64-bit files.
+	elf32FlagsOffset = 0x24
+	elf64FlagsOffset = 0x30
+
+	riscvFloatABIMask   = 0x0006
+	riscvFloatABISoft   = 0x0000
+	riscvFloatABIDouble = 0x0004
+)
+
+// Entry represents a library in an ld.so.cache.
+type Entry struct {
+	// Soname is the SONAME of the library that is used as the key in the cache.
+	Soname string
+	// Path is the path of the library relative to the root of the cache.
+	Path  string
+	Flags int32
+	HWCap uint64
+	// HWCapsSubdir is the glibc-hwcaps subdirectory for libraries that are selected based on
+	// the capabilities of the CPU, for example x86-64-v3. The HWCap is not used for these.
+	HWCapsSubdir string
+}
+
+// Scan returns the entries for the shared libraries in the specified directories. The
+// directories are resolved relative to root and the paths of the returned entries are relative
+// to root. If the SONAME link for a library exists this is used as its path.
+func Scan(logger logger.Interface, root string, dirs ...string) ([]Entry, error) {
+	var entries []Entry
+	for _, dir := range dirs {
+		resolved, err := securejoin.Resolve(root, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %v: %w", dir, err)
+		}
+		files, err := os.ReadDir(resolved)
+		if os.IsNotExist(err) {
+			logger.Debugf("Skipping missing directory %v", dir)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[Entry]bool)
+		for _, file := range files {
+			if !strings.Contains(file.Name(), ".so") {
+				continue
+			}
+			e, err := getEntry(root, filepath.Join(dir, file.Name()))
+			if err != nil {
+				logger.Debugf("Skipping %v: %v", file.Name(), err)
+				continue
+			}
+			if e == nil || seen[*e] {
+				continue
+			}
+			seen[*e] = true
+			entries = append(entries, *e)
+		}
+	}
+	return entries, nil
+}
+
+// getEntry returns the cache entry for the specified file. A nil entry is returned if the file
+// is not a shared library.
+func getEntry(root string, path string) (*Entry, error) {
+	resolved, err := securejoin.Resolve(root, path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	f, err := elf.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if f.Type != elf.ET_DYN {
+		return nil, nil
+	}
+	flags, err := getArchFlags(f, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	soname := filepath.Base(path)
+	sonames, err := f.DynString(elf.DT_SONAME)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SONAME: %v", err)
+	}
+	if len(sonames) > 0 {
+		soname = sonames[0]
+	}
+
+	e := Entry{
+		Soname: soname,
+		Path:   path,
+		Flags:  flagTypeELFLibc6 | flags,
+	}
+	// We use the SONAME link if this exists and points to the same file.
+	sonamePath := filepath.Join(filepath.Dir(path), soname)
+	if linkPath, err := securejoin.Resolve(root, sonamePath); err == nil {
+		if linkInfo, err := os.Stat(linkPath); err == nil && os.SameFile(info, linkInfo) {
+			e.Path = sonamePath
+		}
+	}
+	return &e, nil
+}
+
+// getArchFlags returns the ld.so.cache architecture flags for the specified ELF file.
+func getArchFlags(f *elf.File, path string) (int32, error) {
+	switch {
+	case f.Machine == elf.EM_386:
+		return flagArchI386, nil
+	case f.Machine == elf.EM_X86_64 && f.Class == elf.ELFCLASS64:
+		return flagArchX8664, nil
+	case f.Machine == elf.EM_X86_64 && f.Class == elf.ELFCLASS32:
+		return flagArchX32, nil
+	case f.Machine == elf.EM_PPC64:
+		return flagArchPpc64le, nil
+	case f.Machine == elf.EM_AARCH64:
+		return flagArchAArch64, nil
+	case f.Machine == elf.EM_RISCV && f.Class == elf.ELFCLASS64:
+		eflags, err := getELFFlags(f, path)
+		if err != nil {
+			return 0, err
+		}
+		switch eflags & riscvFloatABIMask {
+		case riscvFloatABISoft:
+			return flagArchRiscvSoft, nil
+		case riscvFloatABIDouble:
+			return flagArchRiscvDouble, nil
+		}
+		return 0, fmt.Errorf("unsupported RISC-V float ABI 0x%x", eflags&riscvFloatABIMask)
+	}
+	return 0, fmt.Errorf("unsupported machine %v (%v)", f.Machine, f.Class)
+}
+
+// getELFFlags reads the processor-specific flags from the header of the ELF file. These are not
+// exposed by the debug/elf package.
+func getELFFlags(f *elf.File, path string) (uint32, error) {
+	offset := int64(elf64FlagsOffset)
+	if f.Class == elf.ELFCLASS32 {
+		offset = elf32FlagsOffset
+	}
+
+	r, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var flags uint32
+	if err := binary.Read(io.NewSectionReader(r, offset, 4), f.ByteOrder, &flags); err != nil {
+		return 0, fmt.Errorf("failed to read ELF flags: %v", err)
+	}
+	return flags, nil
+}
+
+// Entries returns the entries of the ld.so.cache at the specified root. Entries that refer to
+// paths that do not exist are skipped. If no cache exists, no entries are returned.
+func Entries(logger logger.Interface, root string) ([]Entry, error) {
+	cache, err := New(logger, root)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := cache.(*ldcache)
+	if !ok {
+		return nil, nil
+	}
+	defer c.Close()
+
+	var entries []Entry
+	for _, e := range c.entries {
+		existing := Entry{Flags: e.Flags, HWCap: e.HWCap}
+		if isHWCapExtension(e.HWCap) {
+			index := uint32(e.HWCap)
+			if index >= uint32(len(c.hwcapsSubdirs)) {
+				logger.Debugf("Skipping entry with invalid glibc-hwcaps index %d", index)
+				continue
+			}
+			existing = Entry{Flags: e.Flags, HWCapsSubdir: c.hwcapsSubdirs[index]}
+		}
+		entries = appendExisting(logger, entries, root, c.libs, existing, e.Key, e.Value)
+	}
+	for _, e := range c.entries1 {
+		entries = appendExisting(logger, entries, root, c.libs1, Entry{Flags: e.Flags}, e.Key, e.Value)
+	}
+	return entries, nil
+}
+
+// appendExisting completes the specified entry using the string offsets into libs and appends it
+// to entries if the path of the entry exists.
+func appendExisting(logger logger.Interface, entries []Entry, root string, libs []byte, e Entry, key uint32, value uint32) []Entry {
+	if key >= uint32(len(libs)) || value >= uint32(len(libs)) {
+		return entries
+	}
+	e.Soname = bytesToString(libs[key:])
+	e.Path = bytesToString(libs[value:])
+	if e.Soname == "" || e.Path == "" {
+		return entries
+	}
+	resolved, err := securejoin.Resolve(root, e.Path)
+	if err != nil {
+		return entries
+	}
+	if _, err := os.Stat(resolved); err != nil {
+		logger.Debugf("Skipping stale entry %v: %v", e.Path, err)
+		return entries
+	}
+	return append(entries, e)
+}
+
+// Write writes an ld.so.cache in the glibc-ld.so.cache1.1 format containing the specified
+// entries. Only exact duplicates are removed; entries with the same SONAME and flags but
+// different paths are all written and the loader uses the first one in the sorted order.
+// The glibc-hwcaps subdirectories of the entries are written to the extension section.
+func Write(w io.Writer, entries []Entry) error {
+	var unique []Entry
+	seen := make(map[Entry]bool)
+	subdirs := make(map[string]uint32)
+	for _, e := range entries {
+		if isHWCapExtension(e.HWCap) {
+			return fmt.Errorf("invalid hwcap 0x%x for %v; use HWCapsSubdir instead", e.HWCap, e.Path)
+		}
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		unique = append(unique, e)
+		if e.HWCapsSubdir != "" {
+			subdirs[e.HWCapsSubdir] = 0
+		}
+	}
+	hwcapsSubdirs := make([]string, 0, len(subdirs))
+	for subdir := range subdirs {
+		hwcapsSubdirs = append(hwcapsSubdirs, subdir)
+	}
+	sort.Strings(hwcapsSubdirs)
+	for i, subdir := range hwcapsSubdirs {
+		subdirs[subdir] = uint32(i)
+	}
+
+	// The dynamic loader performs a binary search of the entries and expects these to be
+	// sorted in descending order.
+	sort.SliceStable(unique, func(i, j int) bool {
+		a, b := unique[i], unique[j]
+		if c := libcmp(a.Soname, b.Soname); c != 0 {
+			return c > 0
+		}
+		if a.Flags != b.Flags {
+			return a.Flags > b.Flags
+		}
+		// The loader stops at the first regular entry once a glibc-hwcaps entry has been
+		// found, so the glibc-hwcaps entries of a library have to come first.
+		if (a.HWCapsSubdir != "") != (b.HWCapsSubdir != "") {
+			return a.HWCapsSubdir != ""
+		}
+		if a.HWCapsSubdir != b.HWCapsSubdir {
+			return a.HWCapsSubdir < b.HWCapsSubdir
+		}
+		return a.HWCap > b.HWCap
+	})
+
+	// The string table follows the entries and its offsets are relative to the start of the cache.
+	stringsOffset := uint32(unsafe.Sizeof(header2{})) + uint32(len(unique))*uint32(unsafe.Sizeof(entry2{}))
+	var stringTable bytes.Buffer
+	offsets := make(map[string]uint32)
+	addString := func(s string) uint32 {
+		if offset, ok := offsets[s]; ok {
+			return offset
+		}
+		offset := stringsOffset + uint32(stringTable.Len())
+		stringTable.WriteString(s)
+		stringTable.WriteByte(0)
+		offsets[s] = offset
+		return offset
+	}
+
+	cacheEntries := make([]entry2, 0, len(unique))
+	for _, e := range unique {
+		hwcap := e.HWCap
+		if e.HWCapsSubdir != "" {
+			hwcap = hwcapExtension | uint64(subdirs[e.HWCapsSubdir])
+		}
+		cacheEntries = append(cacheEntries, entry2{
+			Flags: e.Flags,
+			Key:   addString(e.Soname),
+			Value: addString(e.Path),
+			HWCap: hwcap,
+		})
+	}
+	subdirOffsets := make([]uint32, 0, len(hwcapsSubdirs))
+	for _, subdir := range hwcapsSubdirs {
+		subdirOffsets = append(subdirOffsets, addString(subdir))
+	}
+
+	header := header2{
+		NLibs:     uint32(len(cacheEntries)),
+		TableSize: uint32(stringTable.Len()),
+		Flags:     headerFlagLittleEndian,
+	}
+	// The extension section follows the string table and is aligned to 4 bytes.
+	var padding []byte
+	if len(subdirOffsets) > 0 {
+		end := stringsOffset + uint32(stringTable.Len())
+		padding = make([]byte, (4-end%4)%4)
+		header.ExtensionOffset = end + uint32(len(padding))
+	}
+	copy(header.Magic[:], magicString2)
+	copy(header.Version[:], magicVersion)
+
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return fmt.Errorf("failed to write header: %v", err)
+	}
+	if err := binary.Write(w, binary.LittleEndian, cacheEntries); err != nil {
+		return fmt.Errorf("failed to write entries: %v", err)
+	}
+	if _, err := stringTable.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write string table: %v", err)
+	}
+	if header.ExtensionOffset == 0 {
+		return nil
+	}
+
+	extension := extensionHeader{
+		Magic: extensionMagic,
+		Count: 1,
+	}
+	section := extensionSection{
+		Tag:    extensionTagHWCaps,
+		Offset: header.ExtensionOffset + uint32(unsafe.Sizeof(extensionHeader{})) + uint32(unsafe.Sizeof(extensionSection{})),
+		Size:   uint32(len(subdirOffsets)) * 4,
+	}
+	for _, data := range []interface{}{padding, &extension, &section, subdirOffsets} {
+		if err := binary.Write(w, binary.LittleEndian, data); err != nil {
+			return fmt.Errorf("failed to write extension section: %v", err)
+		}
+	}
+	return nil
+}
+
+// libcmp compares two library names as is done by the dynamic loader. Sequences of digits are
+// compared numerically and digits sort after all other characters.
+func libcmp(a string, b string) int {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+
+	i, j := 0, 0
+	for i < len(a) {
+		switch {
+		case isDigit(a[i]) && j < len(b) && isDigit(b[j]):
+			var va, vb int
+			for ; i < len(a) && isDigit(a[i]); i++ {
+				va = va*10 + int(a[i]-'0')
+			}
+			for ; j < len(b) && isDigit(b[j]); j++ {
+				vb = vb*10 + int(b[j]-'0')
+			}
+			if va != vb {
+				return va - vb
+			}
+		case isDigit(a[i]):
+			return 1
+		case j < len(b) && isDigit(b[j]):
+			return -1
+		case j >= len(b) || a[i] != b[j]:
+			return int(a[i]) - int(byteAt(b, j))
+		default:
+			i++
+			j++
+		}
+	}
+	return -int(byteAt(b, j))
+}
+
+func byteAt(s string, i int) byte {
+	if i >= len(s) {
+		return 0
+	}
+	return s[i]
+}
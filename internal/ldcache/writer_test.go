@@ -0,0 +1,155 @@
+package ldcache
+
+import (
+	"bytes"
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/test"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibcmp(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "libxdxct.so", b: "libxdxct.so", expected: 0},
+		{a: "libxdxct.so.10", b: "libxdxct.so.9", expected: 1},
+		{a: "libxdxct.so.1", b: "libxdxct.so", expected: 1},
+		{a: "libxdxct.so", b: "libxdxct.so.1", expected: -1},
+		{a: "liba.so", b: "libb.so", expected: -1},
+		{a: "lib2.so", b: "libb.so", expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			c := libcmp(tc.a, tc.b)
+			switch {
+			case tc.expected > 0:
+				require.Positive(t, c)
+			case tc.expected < 0:
+				require.Negative(t, c)
+			default:
+				require.Zero(t, c)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	root := t.TempDir()
+
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/xdxct/libxdxct.so.1.2.3"), elf.ELFCLASS64, elf.EM_X86_64, "libxdxct.so.1")
+	require.NoError(t, os.Symlink("libxdxct.so.1.2.3", filepath.Join(root, "usr/lib/xdxct/libxdxct.so.1")))
+	require.NoError(t, os.Symlink("libxdxct.so.1", filepath.Join(root, "usr/lib/xdxct/libxdxct.so")))
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/xdxct/libxdxct-ml.so.1"), elf.ELFCLASS64, elf.EM_X86_64, "")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "usr/lib/xdxct/libscript.so"), []byte("INPUT(libxdxct.so.1)"), 0644))
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib32/libxdxct.so.1"), elf.ELFCLASS32, elf.EM_386, "libxdxct.so.1")
+
+	entries, err := Scan(logger, root, "/usr/lib/xdxct", "/usr/lib32", "/does/not/exist")
+	require.NoError(t, err)
+	require.ElementsMatch(t,
+		[]Entry{
+			{Soname: "libxdxct.so.1", Path: "/usr/lib/xdxct/libxdxct.so.1", Flags: flagTypeELFLibc6 | flagArchX8664},
+			{Soname: "libxdxct-ml.so.1", Path: "/usr/lib/xdxct/libxdxct-ml.so.1", Flags: flagTypeELFLibc6 | flagArchX8664},
+			{Soname: "libxdxct.so.1", Path: "/usr/lib32/libxdxct.so.1", Flags: flagTypeELFLibc6 | flagArchI386},
+		},
+		entries,
+	)
+
+	var cache bytes.Buffer
+	require.NoError(t, Write(&cache, append(entries, entries[0])))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ldcachePath), cache.Bytes(), 0644))
+
+	c, err := New(logger, root)
+	require.NoError(t, err)
+	libs := c.List()
+	require.Equal(t, []string{filepath.Join(root, "usr/lib32/libxdxct.so.1")}, libs[ArchI386])
+	require.ElementsMatch(t,
+		[]string{
+			filepath.Join(root, "usr/lib/xdxct/libxdxct.so.1.2.3"),
+			filepath.Join(root, "usr/lib/xdxct/libxdxct-ml.so.1"),
+		},
+		libs[ArchX8664],
+	)
+
+	written, err := Entries(logger, root)
+	require.NoError(t, err)
+	require.ElementsMatch(t, entries, written)
+	// The entries are sorted in descending order of their SONAMEs as expected by the loader.
+	require.Equal(t, "libxdxct.so.1", written[0].Soname)
+	require.Equal(t, flagTypeELFLibc6|flagArchX8664, int(written[0].Flags))
+	require.Equal(t, "libxdxct-ml.so.1", written[2].Soname)
+}
+
+func TestWriteHWCap(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	root := t.TempDir()
+	writeSharedLibrary(t, filepath.Join(root, "usr/lib/libxdxct.so.1"), elf.ELFCLASS64, elf.EM_X86_64, "libxdxct.so.1")
+
+	entries := []Entry{
+		{Soname: "libxdxct.so.1", Path: "/usr/lib/libxdxct.so.1", Flags: flagTypeELFLibc6 | flagArchX8664},
+		{Soname: "libxdxct.so.1", Path: "/usr/lib/libxdxct.so.1", Flags: flagTypeELFLibc6 | flagArchX8664, HWCap: 1 << 3},
+	}
+	var cache bytes.Buffer
+	require.NoError(t, Write(&cache, entries))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ldcachePath), cache.Bytes(), 0644))
+
+	written, err := Entries(logger, root)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{entries[1], entries[0]}, written)
+
+	require.Error(t, Write(&cache, []Entry{{Soname: "libxdxct.so.1", Path: "/usr/lib/libxdxct.so.1", HWCap: 1 << 62}}))
+}
+
+func TestWriteHWCapsSubdir(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	root := t.TempDir()
+	for _, path := range []string{
+		"usr/lib/libxdxct.so.1",
+		"usr/lib/libcuda.so.1",
+		"usr/lib/glibc-hwcaps/x86-64-v3/libxdxct.so.1",
+		"usr/lib/glibc-hwcaps/x86-64-v2/libxdxct.so.1",
+	} {
+		writeSharedLibrary(t, filepath.Join(root, path), elf.ELFCLASS64, elf.EM_X86_64, filepath.Base(path))
+	}
+
+	flags := int32(flagTypeELFLibc6 | flagArchX8664)
+	entries := []Entry{
+		{Soname: "libxdxct.so.1", Path: "/usr/lib/libxdxct.so.1", Flags: flags},
+		{Soname: "libxdxct.so.1", Path: "/usr/lib/glibc-hwcaps/x86-64-v3/libxdxct.so.1", Flags: flags, HWCapsSubdir: "x86-64-v3"},
+		{Soname: "libcuda.so.1", Path: "/usr/lib/libcuda.so.1", Flags: flags},
+		{Soname: "libxdxct.so.1", Path: "/usr/lib/glibc-hwcaps/x86-64-v2/libxdxct.so.1", Flags: flags, HWCapsSubdir: "x86-64-v2"},
+	}
+	var cache bytes.Buffer
+	require.NoError(t, Write(&cache, entries))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ldcachePath), cache.Bytes(), 0644))
+
+	c, err := New(logger, root)
+	require.NoError(t, err)
+	require.Equal(t, []string{"x86-64-v2", "x86-64-v3"}, c.(*ldcache).hwcapsSubdirs)
+
+	written, err := Entries(logger, root)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{entries[3], entries[1], entries[0], entries[2]}, written)
+}
+
+// writeSharedLibrary writes a minimal ELF shared library with the specified class, machine and
+// SONAME. Only the sections required to read the SONAME are included.
+func writeSharedLibrary(t *testing.T, path string, class elf.Class, machine elf.Machine, soname string) {
+	writeSharedLibraryWithFlags(t, path, class, machine, 0, soname)
+}
+
+// writeSharedLibraryWithFlags writes a minimal ELF shared library that also includes the
+// specified processor-specific flags.
+func writeSharedLibraryWithFlags(t *testing.T, path string, class elf.Class, machine elf.Machine, flags uint32, soname string) {
+	require.NoError(t, test.WriteSharedLibrary(path, class, machine, flags, soname))
+}
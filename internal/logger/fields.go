@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+)
+
+// hookAdder is implemented by loggers that support logrus hooks.
+type hookAdder interface {
+	AddHook(logrus.Hook)
+}
+
+// hookReplacer is implemented by loggers that allow for their logrus hooks to be replaced.
+type hookReplacer interface {
+	ReplaceHooks(logrus.LevelHooks) logrus.LevelHooks
+}
+
+// AddFields attaches the specified fields to all subsequent log entries of the logger.
+// This is a no-op for loggers that do not support logrus hooks.
+func AddFields(l Interface, fields logrus.Fields) {
+	AddHook(l, &fieldsHook{fields: fields})
+}
+
+// AddHook adds the specified hook to the logger. The hooks that write entries to a log target
+// are kept last so that the entries they write include the fields and modifications of all
+// other hooks, irrespective of the order in which the hooks were added. This is a no-op for
+// loggers that do not support logrus hooks.
+func AddHook(l Interface, hook logrus.Hook) {
+	r, ok := l.(hookReplacer)
+	if !ok {
+		if h, ok := l.(hookAdder); ok {
+			h.AddHook(hook)
+		}
+		return
+	}
+
+	hooks := r.ReplaceHooks(make(logrus.LevelHooks))
+	if hooks == nil {
+		hooks = make(logrus.LevelHooks)
+	}
+	hooks.Add(hook)
+	r.ReplaceHooks(orderTargetsLast(hooks))
+}
+
+// orderTargetsLast returns the specified hooks with the target hooks moved to the end for each level.
+func orderTargetsLast(hooks logrus.LevelHooks) logrus.LevelHooks {
+	ordered := make(logrus.LevelHooks, len(hooks))
+	for level, levelHooks := range hooks {
+		var targets []logrus.Hook
+		for _, h := range levelHooks {
+			if _, ok := h.(*targetHook); ok {
+				targets = append(targets, h)
+				continue
+			}
+			ordered[level] = append(ordered[level], h)
+		}
+		ordered[level] = append(ordered[level], targets...)
+	}
+	return ordered
+}
+
+// NewTraceID returns a random ID that can be used to correlate the log entries of a single invocation.
+func NewTraceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// fieldsHook adds a fixed set of fields to log entries.
+type fieldsHook struct {
+	fields logrus.Fields
+}
+
+// Levels returns the levels for which the hook fires.
+func (h *fieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the fields to the entry. Fields that are already set are not overwritten.
+func (h *fieldsHook) Fire(entry *logrus.Entry) error {
+	for k, v := range h.fields {
+		if v == "" {
+			continue
+		}
+		if _, ok := entry.Data[k]; ok {
+			continue
+		}
+		entry.Data[k] = v
+	}
+	return nil
+}
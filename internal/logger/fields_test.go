@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddFields(t *testing.T) {
+	l, hook := testlog.NewNullLogger()
+
+	AddFields(l, logrus.Fields{"container-id": "abc", "mode": ""})
+	l.WithField("container-id", "override").Info("first")
+	l.Info("second")
+
+	entries := hook.AllEntries()
+	require.Len(t, entries, 2)
+	require.Equal(t, logrus.Fields{"container-id": "override"}, entries[0].Data)
+	require.Equal(t, logrus.Fields{"container-id": "abc"}, entries[1].Data)
+
+	// Loggers without hook support are ignored.
+	AddFields(&NullLogger{}, logrus.Fields{"container-id": "abc"})
+}
+
+// recordingHook records the message and fields of log entries at the time the hook is fired.
+type recordingHook struct {
+	entries []string
+}
+
+func (h *recordingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *recordingHook) Fire(entry *logrus.Entry) error {
+	h.entries = append(h.entries, fmt.Sprintf("%s %v", entry.Message, entry.Data))
+	return nil
+}
+
+func TestAddHookOrdersTargetsLast(t *testing.T) {
+	l, _ := testlog.NewNullLogger()
+
+	target := &recordingHook{}
+	AddHook(l, &targetHook{Hook: target})
+	AddFields(l, logrus.Fields{"container-id": "abc"})
+	AddFields(l, logrus.Fields{"mode": "cdi"})
+
+	l.Info("message")
+
+	require.Equal(t, []string{"message map[container-id:abc mode:cdi]"}, target.entries)
+	for _, hooks := range l.Hooks {
+		_, ok := hooks[len(hooks)-1].(*targetHook)
+		require.True(t, ok)
+	}
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	journaldSocket = "/run/systemd/journal/socket"
+)
+
+// journaldHook sends log entries to the local journald socket using the native journal protocol.
+// Fields of the log entry are sent as journal fields, allowing for entries to be filtered by
+// container ID, for example.
+type journaldHook struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+func newJournaldHook(identifier string) (*journaldHook, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	h := journaldHook{
+		conn:       conn,
+		identifier: identifier,
+	}
+	return &h, nil
+}
+
+// Levels returns the levels for which the hook fires.
+func (h *journaldHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire sends the entry to journald.
+func (h *journaldHook) Fire(entry *logrus.Entry) error {
+	_, err := h.conn.Write(journaldMessage(h.identifier, entry))
+	return err
+}
+
+// journaldMessage serializes the log entry using the native journal protocol.
+func journaldMessage(identifier string, entry *logrus.Entry) []byte {
+	var b bytes.Buffer
+	writeJournaldField(&b, "MESSAGE", entry.Message)
+	writeJournaldField(&b, "PRIORITY", fmt.Sprintf("%d", journaldPriority(entry.Level)))
+	if identifier != "" {
+		writeJournaldField(&b, "SYSLOG_IDENTIFIER", identifier)
+	}
+	for k, v := range entry.Data {
+		name := journaldFieldName(k)
+		if name == "" {
+			continue
+		}
+		writeJournaldField(&b, name, fmt.Sprint(v))
+	}
+	return b.Bytes()
+}
+
+// writeJournaldField writes a single field. Values containing newlines are written with an
+// explicit length as required by the journal protocol.
+func writeJournaldField(b *bytes.Buffer, name string, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journaldFieldName converts a logrus field name to a valid journal field name. Journal
+// field names consist of upper case letters, digits, and underscores and may not start
+// with an underscore or a digit.
+func journaldFieldName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if b.Len() > 0 {
+				b.WriteRune(r)
+			}
+		case b.Len() > 0:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func journaldPriority(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	}
+	return 7
+}
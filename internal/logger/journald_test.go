@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaldMessage(t *testing.T) {
+	entry := &logrus.Entry{
+		Level:   logrus.WarnLevel,
+		Message: "first\nsecond",
+		Data: logrus.Fields{
+			"container-id": "abc",
+		},
+	}
+
+	var expected bytes.Buffer
+	expected.WriteString("MESSAGE\n")
+	_ = binary.Write(&expected, binary.LittleEndian, uint64(len("first\nsecond")))
+	expected.WriteString("first\nsecond\n")
+	expected.WriteString("PRIORITY=4\n")
+	expected.WriteString("SYSLOG_IDENTIFIER=xdxct-ctk\n")
+	expected.WriteString("CONTAINER_ID=abc\n")
+
+	require.Equal(t, expected.Bytes(), journaldMessage("xdxct-ctk", entry))
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	testCases := map[string]string{
+		"container-id": "CONTAINER_ID",
+		"trace.id":     "TRACE_ID",
+		"_private":     "PRIVATE",
+		"1bundle":      "BUNDLE",
+		"-":            "",
+	}
+	for name, expected := range testCases {
+		require.Equal(t, expected, journaldFieldName(name), name)
+	}
+}
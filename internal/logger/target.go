@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/sirupsen/logrus"
+	lsyslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// The following log targets are supported in addition to the configured log files.
+const (
+	TargetFile     = "file"
+	TargetJournald = "journald"
+	TargetSyslog   = "syslog"
+)
+
+// SetTarget configures the logger to additionally write log entries to the specified target
+// using the specified identifier. For the file target, no changes are made. The entries are
+// written after the other hooks of the logger, such as those adding fields, have been fired.
+func SetTarget(l Interface, target string, identifier string) error {
+	var hook logrus.Hook
+	var err error
+	switch target {
+	case "", TargetFile:
+		return nil
+	case TargetJournald:
+		hook, err = newJournaldHook(identifier)
+	case TargetSyslog:
+		hook, err = lsyslog.NewSyslogHook("", "", syslog.LOG_INFO|syslog.LOG_DAEMON, identifier)
+	default:
+		return fmt.Errorf("unsupported log target %q", target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %v: %v", target, err)
+	}
+
+	if _, ok := l.(hookAdder); !ok {
+		return fmt.Errorf("logger does not support log target %q", target)
+	}
+	AddHook(l, &targetHook{Hook: hook})
+	return nil
+}
+
+// targetHook marks a hook that writes log entries to a log target. Target hooks are fired
+// after all other hooks of a logger.
+type targetHook struct {
+	logrus.Hook
+}
@@ -66,16 +66,21 @@ func newLdcacheLocator(opts ...Option) Locator {
 
 // Locate finds the specified libraryname.
 // If the input is a library name, the ldcache is searched otherwise the
-// provided path is resolved as a symlink.
+// provided path is resolved as a symlink. Only libraries for the native
+// architecture are returned.
 func (l ldcacheLocator) Locate(libname string) ([]string, error) {
-	paths32, paths64 := l.cache.Lookup(libname)
-	if len(paths32) > 0 {
-		l.logger.Warningf("Ignoring 32-bit libraries for %v: %v", libname, paths32)
+	arch := ldcache.NativeArch()
+	libs := l.cache.Lookup(libname)
+	for a, paths := range libs {
+		if a == arch {
+			continue
+		}
+		l.logger.Debugf("Ignoring %v libraries for %v: %v", a, libname, paths)
 	}
 
-	if len(paths64) == 0 {
-		return nil, fmt.Errorf("64-bit library %v: %w", libname, ErrNotFound)
+	if len(libs[arch]) == 0 {
+		return nil, fmt.Errorf("%v library %v: %w", arch, libname, ErrNotFound)
 	}
 
-	return paths64, nil
+	return libs[arch], nil
 }
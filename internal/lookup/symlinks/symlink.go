@@ -3,6 +3,7 @@ package symlinks
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 // Resolve returns the link target of the specified filename or the filename if it is not a link.
@@ -17,3 +18,31 @@ func Resolve(filename string) (string, error) {
 
 	return os.Readlink(filename)
 }
+
+// ResolveChain returns the links in the symlink chain starting at the specified path followed by
+// the final target of the chain. The path and absolute link targets are interpreted relative to
+// root and the returned paths include the root.
+func ResolveChain(root string, path string) ([]string, error) {
+	current := filepath.Join(root, path)
+
+	var chain []string
+	seen := make(map[string]bool)
+	for !seen[current] {
+		seen[current] = true
+		chain = append(chain, current)
+
+		target, err := Resolve(current)
+		if err != nil {
+			return nil, err
+		}
+		if target == current {
+			return chain, nil
+		}
+		if filepath.IsAbs(target) {
+			current = filepath.Join(root, target)
+		} else {
+			current = filepath.Join(filepath.Dir(current), target)
+		}
+	}
+	return nil, fmt.Errorf("symlink loop detected resolving %v", path)
+}
@@ -0,0 +1,62 @@
+package symlinks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveChain(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr/lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "usr/lib/libxdxct.so.1.2.3"), nil, 0644))
+	require.NoError(t, os.Symlink("libxdxct.so.1.2.3", filepath.Join(root, "usr/lib/libxdxct.so.1")))
+	require.NoError(t, os.Symlink("/usr/lib/libxdxct.so.1", filepath.Join(root, "usr/lib/libxdxct.so")))
+	require.NoError(t, os.Symlink("loop", filepath.Join(root, "usr/lib/loop")))
+
+	testCases := []struct {
+		path          string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			path:     "/usr/lib/libxdxct.so.1.2.3",
+			expected: []string{"/usr/lib/libxdxct.so.1.2.3"},
+		},
+		{
+			path: "/usr/lib/libxdxct.so",
+			expected: []string{
+				"/usr/lib/libxdxct.so",
+				"/usr/lib/libxdxct.so.1",
+				"/usr/lib/libxdxct.so.1.2.3",
+			},
+		},
+		{
+			path:          "/usr/lib/loop",
+			expectedError: true,
+		},
+		{
+			path:          "/usr/lib/missing",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			chain, err := ResolveChain(root, tc.path)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var expected []string
+			for _, e := range tc.expected {
+				expected = append(expected, filepath.Join(root, e))
+			}
+			require.Equal(t, expected, chain)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/gpus"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/modifier/cdi"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
@@ -79,7 +80,13 @@ func getDevicesFromSpec(logger logger.Interface, ociSpec oci.Spec, cfg *config.C
 		}
 	}
 
-	envDevices := container.DevicesFromEnvvars(visibleDevicesEnvvar)
+	envDevices, err := image.ResolveVisibleDevices(
+		container.DevicesFromEnvvars(visibleDevicesEnvvar),
+		gpus.NewDeviceLister(nil),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve devices requested in %v: %v", visibleDevicesEnvvar, err)
+	}
 
 	var devices []string
 	seen := make(map[string]bool)
@@ -91,6 +98,7 @@ func getDevicesFromSpec(logger logger.Interface, ociSpec oci.Spec, cfg *config.C
 			logger.Debugf("Ignoring duplicate device %q", name)
 			continue
 		}
+		seen[name] = true
 		devices = append(devices, name)
 	}
 
@@ -154,9 +162,9 @@ func filterAutomaticDevices(devices []string) []string {
 	return automatic
 }
 
-func newAutomaticCDISpecModifier(logger logger.Interface, cfg *config.Config, devices []string) (oci.SpecModifier, error) {
+func newAutomaticCDISpecModifier(logger logger.Interface, cfg *config.Config, devices []string, opts ...xdxcdi.Option) (oci.SpecModifier, error) {
 	logger.Debugf("Generating in-memory CDI specs for devices %v", devices)
-	spec, err := generateAutomaticCDISpec(logger, cfg, devices)
+	spec, err := generateAutomaticCDISpec(logger, cfg, devices, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate CDI spec: %w", err)
 	}
@@ -171,13 +179,15 @@ func newAutomaticCDISpecModifier(logger logger.Interface, cfg *config.Config, de
 	return cdiModifier, nil
 }
 
-func generateAutomaticCDISpec(logger logger.Interface, cfg *config.Config, devices []string) (spec.Interface, error) {
+func generateAutomaticCDISpec(logger logger.Interface, cfg *config.Config, devices []string, opts ...xdxcdi.Option) (spec.Interface, error) {
 	cdilib, err := xdxcdi.New(
-		xdxcdi.WithLogger(logger),
-		xdxcdi.WithXDXCTCTKPath(cfg.XDXCTCTKConfig.Path),
-		xdxcdi.WithDriverRoot(cfg.XDXCTContainerCLIConfig.Root),
-		xdxcdi.WithVendor("xdxct.com"),
-		xdxcdi.WithClass("gpu"),
+		append([]xdxcdi.Option{
+			xdxcdi.WithLogger(logger),
+			xdxcdi.WithXDXCTCTKPath(cfg.XDXCTCTKConfig.Path),
+			xdxcdi.WithDriverRoot(cfg.XDXCTContainerCLIConfig.Root),
+			xdxcdi.WithVendor("xdxct.com"),
+			xdxcdi.WithClass("gpu"),
+		}, opts...)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct CDI library: %w", err)
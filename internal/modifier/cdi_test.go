@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
 )
 
 func TestGetAnnotationDevices(t *testing.T) {
@@ -74,3 +79,44 @@ func TestGetAnnotationDevices(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDevicesFromSpec(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description     string
+		env             []string
+		expectedDevices []string
+	}{
+		{
+			description: "no devices",
+		},
+		{
+			description:     "unqualified names use the default kind",
+			env:             []string{"XDXCT_VISIBLE_DEVICES=0,1"},
+			expectedDevices: []string{"xdxct.com/gpu=0", "xdxct.com/gpu=1"},
+		},
+		{
+			description:     "duplicate devices are removed",
+			env:             []string{"XDXCT_VISIBLE_DEVICES=0,xdxct.com/gpu=0,1,0"},
+			expectedDevices: []string{"xdxct.com/gpu=0", "xdxct.com/gpu=1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg, err := config.GetDefault()
+			require.NoError(t, err)
+
+			ociSpec := &oci.SpecMock{
+				LoadFunc: func() (*specs.Spec, error) {
+					return &specs.Spec{Process: &specs.Process{Env: tc.env}}, nil
+				},
+			}
+
+			devices, err := getDevicesFromSpec(logger, ociSpec, cfg)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDevices, devices)
+		})
+	}
+}
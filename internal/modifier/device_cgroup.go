@@ -0,0 +1,190 @@
+package modifier
+
+import (
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	deviceCgroupAccess = "rwm"
+)
+
+// deviceCgroupRules is a spec modifier that wraps another modifier and ensures that a
+// linux.resources.devices allow rule exists for each device node injected by the wrapped modifier.
+type deviceCgroupRules struct {
+	logger   logger.Interface
+	modifier oci.SpecModifier
+}
+
+var _ oci.SpecModifier = (*deviceCgroupRules)(nil)
+
+// NewDeviceCgroupRulesModifier wraps the specified modifier so that device cgroup allow rules
+// are added for all device nodes that it injects. This is required on cgroup v1 hosts where the
+// container engine specifies a restrictive device list. If the feature is not enabled in the
+// config, the specified modifier is returned as is.
+func NewDeviceCgroupRulesModifier(logger logger.Interface, cfg *config.Config, modifier oci.SpecModifier) oci.SpecModifier {
+	if modifier == nil || !cfg.XDXCTContainerRuntimeConfig.DeviceCgroupRules {
+		return modifier
+	}
+
+	m := deviceCgroupRules{
+		logger:   logger,
+		modifier: modifier,
+	}
+	return &m
+}
+
+// Modify applies the wrapped modifier and adds allow rules for the injected device nodes.
+// Rules that are already present are not duplicated.
+func (m deviceCgroupRules) Modify(spec *specs.Spec) error {
+	existing := make(map[string]specs.LinuxDevice)
+	if spec.Linux != nil {
+		for _, d := range spec.Linux.Devices {
+			existing[d.Path] = d
+		}
+	}
+
+	if err := m.modifier.Modify(spec); err != nil {
+		return err
+	}
+	if spec.Linux == nil {
+		return nil
+	}
+
+	for _, d := range spec.Linux.Devices {
+		if e, ok := existing[d.Path]; ok && isSameDevice(e, d) {
+			continue
+		}
+		if d.Type != "c" && d.Type != "b" {
+			continue
+		}
+		if spec.Linux.Resources == nil {
+			spec.Linux.Resources = &specs.LinuxResources{}
+		}
+		if isDeviceAllowed(spec.Linux.Resources.Devices, d) {
+			m.logger.Debugf("Device cgroup access for %v (%v %d:%d) already allowed", d.Path, d.Type, d.Major, d.Minor)
+			continue
+		}
+
+		m.logger.Infof("Allowing device cgroup access for %v (%v %d:%d)", d.Path, d.Type, d.Major, d.Minor)
+		major, minor := d.Major, d.Minor
+		spec.Linux.Resources.Devices = append(spec.Linux.Resources.Devices, specs.LinuxDeviceCgroup{
+			Allow:  true,
+			Type:   d.Type,
+			Major:  &major,
+			Minor:  &minor,
+			Access: deviceCgroupAccess,
+		})
+	}
+
+	if spec.Linux.Resources != nil {
+		spec.Linux.Resources.Devices = removeRedundantRules(spec.Linux.Resources.Devices)
+	}
+
+	return nil
+}
+
+// isDeviceAllowed checks whether the specified rules allow full access to the specified device.
+// Since later rules take precedence, the last rule that matches the device determines the result.
+func isDeviceAllowed(rules []specs.LinuxDeviceCgroup, d specs.LinuxDevice) bool {
+	for i := len(rules) - 1; i >= 0; i-- {
+		r := rules[i]
+		if !ruleMatches(r, d.Type, d.Major, d.Minor) {
+			continue
+		}
+		if !r.Allow {
+			return false
+		}
+		if hasAccess(r.Access, deviceCgroupAccess) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeRedundantRules removes rules that are exact duplicates of an earlier rule where no
+// conflicting rule for the same devices is defined between the two.
+func removeRedundantRules(rules []specs.LinuxDeviceCgroup) []specs.LinuxDeviceCgroup {
+	var filtered []specs.LinuxDeviceCgroup
+	for _, r := range rules {
+		if !isRedundant(filtered, r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func isRedundant(previous []specs.LinuxDeviceCgroup, r specs.LinuxDeviceCgroup) bool {
+	for i := len(previous) - 1; i >= 0; i-- {
+		p := previous[i]
+		if isSameRule(p, r) {
+			return true
+		}
+		if rulesOverlap(p, r) {
+			return false
+		}
+	}
+	return false
+}
+
+func ruleMatches(r specs.LinuxDeviceCgroup, deviceType string, major int64, minor int64) bool {
+	if r.Type != "" && r.Type != "a" && r.Type != deviceType {
+		return false
+	}
+	if r.Major != nil && *r.Major != -1 && *r.Major != major {
+		return false
+	}
+	if r.Minor != nil && *r.Minor != -1 && *r.Minor != minor {
+		return false
+	}
+	return true
+}
+
+// rulesOverlap checks whether there is a device that is matched by both rules.
+func rulesOverlap(a specs.LinuxDeviceCgroup, b specs.LinuxDeviceCgroup) bool {
+	isWildcardType := func(t string) bool { return t == "" || t == "a" }
+	if !isWildcardType(a.Type) && !isWildcardType(b.Type) && a.Type != b.Type {
+		return false
+	}
+	return numbersOverlap(a.Major, b.Major) && numbersOverlap(a.Minor, b.Minor)
+}
+
+func numbersOverlap(a *int64, b *int64) bool {
+	if a == nil || b == nil || *a == -1 || *b == -1 {
+		return true
+	}
+	return *a == *b
+}
+
+func isSameRule(a specs.LinuxDeviceCgroup, b specs.LinuxDeviceCgroup) bool {
+	return a.Allow == b.Allow && a.Type == b.Type && a.Access == b.Access &&
+		isSameNumber(a.Major, b.Major) && isSameNumber(a.Minor, b.Minor)
+}
+
+func isSameNumber(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func isSameDevice(a specs.LinuxDevice, b specs.LinuxDevice) bool {
+	return a.Type == b.Type && a.Major == b.Major && a.Minor == b.Minor
+}
+
+// hasAccess checks whether the access string includes all the required permissions.
+func hasAccess(access string, required string) bool {
+	if access == "" {
+		access = deviceCgroupAccess
+	}
+	for _, c := range required {
+		if !strings.ContainsRune(access, c) {
+			return false
+		}
+	}
+	return true
+}
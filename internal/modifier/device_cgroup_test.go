@@ -0,0 +1,144 @@
+package modifier
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+)
+
+type modifierFunc func(*specs.Spec) error
+
+func (f modifierFunc) Modify(spec *specs.Spec) error {
+	return f(spec)
+}
+
+func TestDeviceCgroupRulesModifier(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	int64Ptr := func(i int64) *int64 { return &i }
+	injectDevices := modifierFunc(func(spec *specs.Spec) error {
+		if spec.Linux == nil {
+			spec.Linux = &specs.Linux{}
+		}
+		spec.Linux.Devices = append(spec.Linux.Devices,
+			specs.LinuxDevice{Path: "/dev/dri/card0", Type: "c", Major: 226, Minor: 0},
+			specs.LinuxDevice{Path: "/dev/dri/renderD128", Type: "c", Major: 226, Minor: 128},
+		)
+		return nil
+	})
+
+	testCases := []struct {
+		description   string
+		spec          *specs.Spec
+		expectedRules []specs.LinuxDeviceCgroup
+	}{
+		{
+			description: "rules are added for injected devices",
+			spec:        &specs.Spec{},
+			expectedRules: []specs.LinuxDeviceCgroup{
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(0), Access: "rwm"},
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(128), Access: "rwm"},
+			},
+		},
+		{
+			description: "existing rules are not duplicated",
+			spec: &specs.Spec{
+				Linux: &specs.Linux{
+					Resources: &specs.LinuxResources{
+						Devices: []specs.LinuxDeviceCgroup{
+							{Allow: false, Access: "rwm"},
+							{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(0), Access: "rwm"},
+						},
+					},
+				},
+			},
+			expectedRules: []specs.LinuxDeviceCgroup{
+				{Allow: false, Access: "rwm"},
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(0), Access: "rwm"},
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(128), Access: "rwm"},
+			},
+		},
+		{
+			description: "wildcard rule allows all injected devices",
+			spec: &specs.Spec{
+				Linux: &specs.Linux{
+					Resources: &specs.LinuxResources{
+						Devices: []specs.LinuxDeviceCgroup{
+							{Allow: true, Type: "c", Major: int64Ptr(226), Access: "rwm"},
+						},
+					},
+				},
+			},
+			expectedRules: []specs.LinuxDeviceCgroup{
+				{Allow: true, Type: "c", Major: int64Ptr(226), Access: "rwm"},
+			},
+		},
+		{
+			description: "rule is added after a later deny rule",
+			spec: &specs.Spec{
+				Linux: &specs.Linux{
+					Resources: &specs.LinuxResources{
+						Devices: []specs.LinuxDeviceCgroup{
+							{Allow: true, Type: "c", Major: int64Ptr(226), Access: "rwm"},
+							{Allow: false, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(128), Access: "rwm"},
+						},
+					},
+				},
+			},
+			expectedRules: []specs.LinuxDeviceCgroup{
+				{Allow: true, Type: "c", Major: int64Ptr(226), Access: "rwm"},
+				{Allow: false, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(128), Access: "rwm"},
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(128), Access: "rwm"},
+			},
+		},
+		{
+			description: "duplicate rules are removed",
+			spec: &specs.Spec{
+				Linux: &specs.Linux{
+					Resources: &specs.LinuxResources{
+						Devices: []specs.LinuxDeviceCgroup{
+							{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(0), Access: "rwm"},
+							{Allow: true, Type: "c", Major: int64Ptr(1), Minor: int64Ptr(3), Access: "rwm"},
+							{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(0), Access: "rwm"},
+						},
+					},
+				},
+			},
+			expectedRules: []specs.LinuxDeviceCgroup{
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(0), Access: "rwm"},
+				{Allow: true, Type: "c", Major: int64Ptr(1), Minor: int64Ptr(3), Access: "rwm"},
+				{Allow: true, Type: "c", Major: int64Ptr(226), Minor: int64Ptr(128), Access: "rwm"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.XDXCTContainerRuntimeConfig.DeviceCgroupRules = true
+
+			m := NewDeviceCgroupRulesModifier(logger, cfg, injectDevices)
+			require.NoError(t, m.Modify(tc.spec))
+
+			require.EqualValues(t, tc.expectedRules, tc.spec.Linux.Resources.Devices)
+
+			// Applying the modifier again must not add further rules.
+			require.NoError(t, m.Modify(tc.spec))
+			require.EqualValues(t, tc.expectedRules, tc.spec.Linux.Resources.Devices)
+		})
+	}
+}
+
+func TestDeviceCgroupRulesModifierDisabled(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	m := modifierFunc(func(*specs.Spec) error { return nil })
+	require.Nil(t, NewDeviceCgroupRulesModifier(logger, &config.Config{}, nil))
+	require.NotNil(t, NewDeviceCgroupRulesModifier(logger, &config.Config{}, m))
+	_, isWrapped := NewDeviceCgroupRulesModifier(logger, &config.Config{}, m).(*deviceCgroupRules)
+	require.False(t, isWrapped)
+}
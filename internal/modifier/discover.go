@@ -11,16 +11,19 @@ import (
 )
 
 type discoverModifier struct {
-	logger     logger.Interface
-	discoverer discover.Discover
+	logger       logger.Interface
+	discoverer   discover.Discover
+	editsOptions []edits.Option
 }
 
 // NewModifierFromDiscoverer creates a modifier that applies the discovered
 // modifications to an OCI spec if required by the runtime wrapper.
-func NewModifierFromDiscoverer(logger logger.Interface, d discover.Discover) (oci.SpecModifier, error) {
+// The specified options are used when constructing the required spec edits.
+func NewModifierFromDiscoverer(logger logger.Interface, d discover.Discover, opts ...edits.Option) (oci.SpecModifier, error) {
 	m := discoverModifier{
-		logger:     logger,
-		discoverer: d,
+		logger:       logger,
+		discoverer:   d,
+		editsOptions: opts,
 	}
 	return &m, nil
 }
@@ -28,7 +31,7 @@ func NewModifierFromDiscoverer(logger logger.Interface, d discover.Discover) (oc
 // Modify applies the modifications required by discoverer to the incomming OCI spec.
 // These modifications are applied in-place.
 func (m discoverModifier) Modify(spec *specs.Spec) error {
-	specEdits, err := edits.NewSpecEdits(m.logger, m.discoverer)
+	specEdits, err := edits.NewSpecEdits(m.logger, m.discoverer, m.editsOptions...)
 	if err != nil {
 		return fmt.Errorf("failed to get required container edits: %v", err)
 	}
@@ -0,0 +1,180 @@
+package modifier
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	"tags.cncf.io/container-device-interface/pkg/parser"
+	cdispecs "tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/drm"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/gpus"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+const (
+	driverVersionEnvvar = "XDXCT_DRIVER_VERSION"
+	gpuEnvvarPrefix     = "XDXCT_GPU_"
+)
+
+// gpuEnv is a spec modifier that sets environment variables describing the GPUs injected into a container.
+type gpuEnv struct {
+	edits *cdi.ContainerEdits
+}
+
+var _ oci.SpecModifier = (*gpuEnv)(nil)
+
+// NewGPUEnvModifier creates a modifier that sets environment variables describing the GPUs requested
+// by the container. These include XDXCT_VISIBLE_DEVICES normalized to a list of UUIDs, the UUID, PCI bus
+// ID, and DRM device nodes for each device index in the container, and the driver version. The
+// variables are applied as CDI container edits so that the environment is the same in all modes.
+// Failures to determine the devices are logged and result in no modification.
+func NewGPUEnvModifier(logger logger.Interface, cfg *config.Config, ociSpec oci.Spec) (oci.SpecModifier, error) {
+	if !cfg.XDXCTContainerRuntimeConfig.GPUEnvironment {
+		return nil, nil
+	}
+
+	names, err := getDevicesFromSpec(logger, ociSpec, cfg)
+	if err != nil {
+		logger.Warningf("Failed to get requested devices; not setting GPU environment: %v", err)
+		return nil, nil
+	}
+	ids := getGPUDeviceIDs(cfg.XDXCTContainerRuntimeConfig.Modes.CDI.DefaultKind, names)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	available, err := gpus.NewDeviceLister(nil).ListDevices()
+	if err != nil {
+		logger.Warningf("Failed to list devices; not setting GPU environment: %v", err)
+		return nil, nil
+	}
+	devices := selectGPUDevices(ids, available)
+	if len(devices) == 0 {
+		logger.Debugf("No devices found matching %v; not setting GPU environment", ids)
+		return nil, nil
+	}
+
+	driverVersion, err := info.GetDriverVersion("/")
+	if err != nil {
+		logger.Debugf("Failed to get driver version: %v", err)
+	}
+
+	env, err := getGPUEnv(devices, driverVersion, drm.GetDeviceNodesByBusID)
+	if err != nil {
+		logger.Warningf("Failed to construct GPU environment: %v", err)
+		return nil, nil
+	}
+	logger.Debugf("Setting GPU environment %v", env)
+
+	m := gpuEnv{
+		edits: &cdi.ContainerEdits{
+			ContainerEdits: &cdispecs.ContainerEdits{
+				Env: env,
+			},
+		},
+	}
+	return &m, nil
+}
+
+// Modify applies the environment edits to the spec. Since CDI env edits are appended to the
+// process environment, existing values for the edited variables are removed first.
+func (m gpuEnv) Modify(spec *specs.Spec) error {
+	if spec.Process != nil {
+		edited := make(map[string]bool)
+		for _, e := range m.edits.Env {
+			key, _, _ := strings.Cut(e, "=")
+			edited[key] = true
+		}
+		var env []string
+		for _, e := range spec.Process.Env {
+			key, _, _ := strings.Cut(e, "=")
+			if edited[key] {
+				continue
+			}
+			env = append(env, e)
+		}
+		spec.Process.Env = env
+	}
+	return m.edits.Apply(spec)
+}
+
+// getGPUDeviceIDs returns the device IDs from the requested CDI devices of the specified kind.
+func getGPUDeviceIDs(kind string, names []string) []string {
+	var ids []string
+	for _, name := range names {
+		if !parser.IsQualifiedName(name) {
+			ids = append(ids, name)
+			continue
+		}
+		vendor, class, id, err := parser.ParseQualifiedName(name)
+		if err != nil || vendor+"/"+class != kind {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// selectGPUDevices returns the available devices matching the specified IDs in the order in which
+// the IDs are specified. Devices can be selected by index, UUID, or PCI bus ID, or all devices
+// can be selected with "all".
+func selectGPUDevices(ids []string, available []image.Device) []image.Device {
+	var selected []image.Device
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		for _, d := range available {
+			if seen[d.Index] {
+				continue
+			}
+			if id != "all" && id != strconv.Itoa(d.Index) && id != d.UUID && !strings.EqualFold(id, d.PCIBusID) {
+				continue
+			}
+			seen[d.Index] = true
+			selected = append(selected, d)
+		}
+	}
+	return selected
+}
+
+// getGPUEnv returns the environment variables for the specified devices. The devices are indexed
+// in the order in which they are specified.
+func getGPUEnv(devices []image.Device, driverVersion string, getDeviceNodes func(string) ([]string, error)) ([]string, error) {
+	var uuids []string
+	var env []string
+	for i, d := range devices {
+		prefix := gpuEnvvarPrefix + strconv.Itoa(i) + "_"
+		uuids = append(uuids, d.UUID)
+		env = append(env,
+			prefix+"UUID="+d.UUID,
+			prefix+"PCI_BUS_ID="+d.PCIBusID,
+		)
+
+		nodes, err := getDeviceNodes(d.PCIBusID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device nodes for %v: %v", d.PCIBusID, err)
+		}
+		for _, node := range nodes {
+			switch base := filepath.Base(node); {
+			case strings.HasPrefix(base, "card"):
+				env = append(env, prefix+"CARD="+node)
+			case strings.HasPrefix(base, "renderD"):
+				env = append(env, prefix+"RENDER="+node)
+			}
+		}
+	}
+
+	env = append(env, visibleDevicesEnvvar+"="+strings.Join(uuids, ","))
+	if driverVersion != "" {
+		env = append(env, driverVersionEnvvar+"="+driverVersion)
+	}
+	return env, nil
+}
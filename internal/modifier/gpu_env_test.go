@@ -0,0 +1,111 @@
+package modifier
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	cdispecs "tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+)
+
+func TestGetGPUDeviceIDs(t *testing.T) {
+	ids := getGPUDeviceIDs("xdxct.com/gpu", []string{"xdxct.com/gpu=0", "example.com/device=1", "xdxct.com/gpu=all", "GPU-1"})
+	require.Equal(t, []string{"0", "all", "GPU-1"}, ids)
+}
+
+func TestSelectGPUDevices(t *testing.T) {
+	available := []image.Device{
+		{Index: 0, UUID: "GPU-0", PCIBusID: "0000:01:00.0"},
+		{Index: 1, UUID: "GPU-1", PCIBusID: "0000:02:00.0"},
+	}
+
+	testCases := []struct {
+		description     string
+		ids             []string
+		expectedIndices []int
+	}{
+		{
+			description:     "all devices",
+			ids:             []string{"all"},
+			expectedIndices: []int{0, 1},
+		},
+		{
+			description:     "order is preserved",
+			ids:             []string{"GPU-1", "0"},
+			expectedIndices: []int{1, 0},
+		},
+		{
+			description:     "duplicates are removed",
+			ids:             []string{"1", "GPU-1", "0000:02:00.0"},
+			expectedIndices: []int{1},
+		},
+		{
+			description: "unknown device",
+			ids:         []string{"GPU-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var indices []int
+			for _, d := range selectGPUDevices(tc.ids, available) {
+				indices = append(indices, d.Index)
+			}
+			require.Equal(t, tc.expectedIndices, indices)
+		})
+	}
+}
+
+func TestGetGPUEnv(t *testing.T) {
+	devices := []image.Device{
+		{Index: 1, UUID: "GPU-1", PCIBusID: "0000:02:00.0"},
+		{Index: 0, UUID: "GPU-0", PCIBusID: "0000:01:00.0"},
+	}
+	nodes := map[string][]string{
+		"0000:01:00.0": {"/dev/dri/card0", "/dev/dri/renderD128"},
+		"0000:02:00.0": {"/dev/dri/card1", "/dev/dri/renderD129"},
+	}
+	getDeviceNodes := func(busID string) ([]string, error) {
+		return nodes[busID], nil
+	}
+
+	env, err := getGPUEnv(devices, "1.2.3", getDeviceNodes)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"XDXCT_GPU_0_UUID=GPU-1",
+		"XDXCT_GPU_0_PCI_BUS_ID=0000:02:00.0",
+		"XDXCT_GPU_0_CARD=/dev/dri/card1",
+		"XDXCT_GPU_0_RENDER=/dev/dri/renderD129",
+		"XDXCT_GPU_1_UUID=GPU-0",
+		"XDXCT_GPU_1_PCI_BUS_ID=0000:01:00.0",
+		"XDXCT_GPU_1_CARD=/dev/dri/card0",
+		"XDXCT_GPU_1_RENDER=/dev/dri/renderD128",
+		"XDXCT_VISIBLE_DEVICES=GPU-1,GPU-0",
+		"XDXCT_DRIVER_VERSION=1.2.3",
+	}, env)
+
+	_, err = getGPUEnv(devices, "", func(string) ([]string, error) { return nil, fmt.Errorf("no sysfs") })
+	require.Error(t, err)
+}
+
+func TestGPUEnvModifierReplacesVisibleDevices(t *testing.T) {
+	m := gpuEnv{
+		edits: &cdi.ContainerEdits{
+			ContainerEdits: &cdispecs.ContainerEdits{
+				Env: []string{"XDXCT_VISIBLE_DEVICES=GPU-0", "XDXCT_GPU_0_UUID=GPU-0"},
+			},
+		},
+	}
+	spec := &specs.Spec{
+		Process: &specs.Process{
+			Env: []string{"PATH=/usr/bin", "XDXCT_VISIBLE_DEVICES=all"},
+		},
+	}
+
+	require.NoError(t, m.Modify(spec))
+	require.Equal(t, []string{"PATH=/usr/bin", "XDXCT_VISIBLE_DEVICES=GPU-0", "XDXCT_GPU_0_UUID=GPU-0"}, spec.Process.Env)
+}
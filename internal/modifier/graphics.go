@@ -6,6 +6,8 @@ import (
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/discover"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/edits"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/gpus"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup/root"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
@@ -13,8 +15,9 @@ import (
 
 // NewGraphicsModifier constructs a modifier that injects graphics-related modifications into an OCI runtime specification.
 // The value of the XDXCT_DRIVER_CAPABILITIES environment variable is checked to determine if this modification should be made.
-func NewGraphicsModifier(logger logger.Interface, cfg *config.Config, image image.GPU) (oci.SpecModifier, error) {
-	if required, reason := requiresGraphicsModifier(image); !required {
+// The specified edits options are applied when the modifications are made.
+func NewGraphicsModifier(logger logger.Interface, cfg *config.Config, gpuImage image.GPU, opts ...edits.Option) (oci.SpecModifier, error) {
+	if required, reason := requiresGraphicsModifier(gpuImage); !required {
 		logger.Infof("No graphics modifier required: %v", reason)
 		return nil, nil
 	}
@@ -32,11 +35,19 @@ func NewGraphicsModifier(logger logger.Interface, cfg *config.Config, image imag
 		return nil, fmt.Errorf("failed to create mounts discoverer: %v", err)
 	}
 
+	devices, err := image.ResolveVisibleDevices(
+		gpuImage.DevicesFromEnvvars(visibleDevicesEnvvar),
+		gpus.NewDeviceLister(nil),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve requested devices: %v", err)
+	}
+
 	// In standard usage, the devRoot is the same as the driver.Root.
 	devRoot := driver.Root
 	drmNodes, err := discover.NewDRMNodesDiscoverer(
 		logger,
-		image.DevicesFromEnvvars(visibleDevicesEnvvar),
+		devices,
 		devRoot,
 		xdxctCTKPath,
 	)
@@ -48,7 +59,7 @@ func NewGraphicsModifier(logger logger.Interface, cfg *config.Config, image imag
 		drmNodes,
 		mounts,
 	)
-	return NewModifierFromDiscoverer(logger, d)
+	return NewModifierFromDiscoverer(logger, d, opts...)
 }
 
 // requiresGraphicsModifier determines whether a graphics modifier is required.
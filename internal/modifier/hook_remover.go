@@ -16,7 +16,8 @@ type xdxctContainerRuntimeHookRemover struct {
 
 var _ oci.SpecModifier = (*xdxctContainerRuntimeHookRemover)(nil)
 
-// Modify removes any XDXCT Container Runtime hooks from the provided spec
+// Modify removes any XDXCT Container Runtime hooks from the provided spec. Hooks are removed
+// from each of the lifecycles at which they can be inserted, including poststart and poststop.
 func (m xdxctContainerRuntimeHookRemover) Modify(spec *specs.Spec) error {
 	if spec == nil {
 		return nil
@@ -26,23 +27,24 @@ func (m xdxctContainerRuntimeHookRemover) Modify(spec *specs.Spec) error {
 		return nil
 	}
 
-	if len(spec.Hooks.Prestart) == 0 {
-		return nil
-	}
-
-	var newPrestart []specs.Hook
-
-	for _, hook := range spec.Hooks.Prestart {
-		if isXDXCTContainerRuntimeHook(&hook) {
-			m.logger.Debugf("Removing hook %v", hook)
+	for lifecycle, hooks := range allRuntimeHookLifecycles(spec.Hooks) {
+		if len(*hooks) == 0 {
 			continue
 		}
-		newPrestart = append(newPrestart, hook)
-	}
 
-	if len(newPrestart) != len(spec.Hooks.Prestart) {
-		m.logger.Debugf("Updating 'prestart' hooks to %v", newPrestart)
-		spec.Hooks.Prestart = newPrestart
+		var filtered []specs.Hook
+		for _, hook := range *hooks {
+			if isXDXCTContainerRuntimeHook(&hook) {
+				m.logger.Debugf("Removing hook %v", hook)
+				continue
+			}
+			filtered = append(filtered, hook)
+		}
+
+		if len(filtered) != len(*hooks) {
+			m.logger.Debugf("Updating '%v' hooks to %v", lifecycle, filtered)
+			*hooks = filtered
+		}
 	}
 
 	return nil
@@ -58,6 +58,37 @@ func TestHookRemover(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "modification removes poststart and poststop xdxct-container-runtime-hooks",
+			spec: &specs.Spec{
+				Hooks: &specs.Hooks{
+					Poststart: []specs.Hook{
+						{
+							Path: "/path/to/xdxct-container-runtime-hook",
+							Args: []string{"/path/to/xdxct-container-runtime-hook", "poststart"},
+						},
+					},
+					Poststop: []specs.Hook{
+						{
+							Path: "/path/to/xdxct-container-runtime-hook",
+							Args: []string{"/path/to/xdxct-container-runtime-hook", "poststop"},
+						},
+						{
+							Path: "/hook/a",
+						},
+					},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				Hooks: &specs.Hooks{
+					Poststop: []specs.Hook{
+						{
+							Path: "/hook/a",
+						},
+					},
+				},
+			},
+		},
 		{
 			description: "modification removes existing xdxct-container-toolkit",
 			spec: &specs.Spec{
@@ -76,6 +107,40 @@ func TestHookRemover(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "modification removes hooks at createRuntime and createContainer",
+			spec: &specs.Spec{
+				Hooks: &specs.Hooks{
+					CreateRuntime: []specs.Hook{
+						{
+							Path: "/path/to/xdxct-container-runtime-hook",
+							Args: []string{"/path/to/xdxct-container-runtime-hook", "createRuntime"},
+						},
+					},
+					CreateContainer: []specs.Hook{
+						{
+							Path: "/hook/a",
+							Args: []string{"/hook/a", "arga"},
+						},
+						{
+							Path: "/path/to/xdxct-container-runtime-hook",
+							Args: []string{"/path/to/xdxct-container-runtime-hook", "createContainer"},
+						},
+					},
+				},
+			},
+			expectedSpec: &specs.Spec{
+				Hooks: &specs.Hooks{
+					CreateRuntime: nil,
+					CreateContainer: []specs.Hook{
+						{
+							Path: "/hook/a",
+							Args: []string{"/hook/a", "arga"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,60 @@
+package modifier
+
+import (
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// hookTimeouts is a spec modifier that wraps another modifier and sets the configured timeouts
+// for the xdxct-ctk hooks in the modified spec.
+type hookTimeouts struct {
+	logger   logger.Interface
+	timeouts config.HookTimeouts
+	modifier oci.SpecModifier
+}
+
+var _ oci.SpecModifier = (*hookTimeouts)(nil)
+
+// NewHookTimeoutsModifier wraps the specified modifier so that the xdxct-ctk hooks injected
+// into the spec are run with the timeouts configured in the xdxct-ctk section of the config.
+// Hooks that already have a timeout are not modified.
+func NewHookTimeoutsModifier(logger logger.Interface, cfg *config.Config, modifier oci.SpecModifier) oci.SpecModifier {
+	if modifier == nil || len(cfg.XDXCTCTKConfig.HookTimeouts) == 0 {
+		return modifier
+	}
+
+	m := hookTimeouts{
+		logger:   logger,
+		timeouts: cfg.XDXCTCTKConfig.HookTimeouts,
+		modifier: modifier,
+	}
+	return &m
+}
+
+// Modify applies the wrapped modifier and sets the timeouts of the xdxct-ctk hooks.
+func (m hookTimeouts) Modify(spec *specs.Spec) error {
+	if err := m.modifier.Modify(spec); err != nil {
+		return err
+	}
+	if spec == nil || spec.Hooks == nil {
+		return nil
+	}
+
+	for _, hooks := range runtimeHookLifecycles(spec.Hooks) {
+		for i, hook := range *hooks {
+			if hook.Timeout != nil {
+				continue
+			}
+			timeout := m.timeouts.Get(hook.Path, hook.Args)
+			if timeout == nil {
+				continue
+			}
+			m.logger.Debugf("Setting timeout of hook %v to %ds", hook.Args, *timeout)
+			(*hooks)[i].Timeout = timeout
+		}
+	}
+
+	return nil
+}
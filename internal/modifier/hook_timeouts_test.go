@@ -0,0 +1,66 @@
+package modifier
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+)
+
+func TestHookTimeoutsModifier(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	intPtr := func(i int) *int { return &i }
+	injectHooks := modifierFunc(func(spec *specs.Spec) error {
+		if spec.Hooks == nil {
+			spec.Hooks = &specs.Hooks{}
+		}
+		spec.Hooks.CreateContainer = append(spec.Hooks.CreateContainer,
+			specs.Hook{Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "update-ldcache"}},
+			specs.Hook{Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "create-symlinks"}},
+			specs.Hook{Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "chmod"}, Timeout: intPtr(5)},
+			specs.Hook{Path: "/usr/bin/other-hook", Args: []string{"other-hook", "hook", "update-ldcache"}},
+		)
+		return nil
+	})
+
+	testCases := []struct {
+		description      string
+		timeouts         config.HookTimeouts
+		expectedTimeouts []*int
+	}{
+		{
+			description:      "no timeouts configured",
+			expectedTimeouts: []*int{nil, nil, intPtr(5), nil},
+		},
+		{
+			description:      "named and default timeouts",
+			timeouts:         config.HookTimeouts{config.DefaultHookTimeoutKey: 30, "update-ldcache": 120},
+			expectedTimeouts: []*int{intPtr(120), intPtr(30), intPtr(5), nil},
+		},
+		{
+			description:      "zero disables the timeout",
+			timeouts:         config.HookTimeouts{config.DefaultHookTimeoutKey: 30, "update-ldcache": 0},
+			expectedTimeouts: []*int{nil, intPtr(30), intPtr(5), nil},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := &config.Config{XDXCTCTKConfig: config.CTKConfig{HookTimeouts: tc.timeouts}}
+			spec := &specs.Spec{}
+
+			m := NewHookTimeoutsModifier(logger, cfg, injectHooks)
+			require.NoError(t, m.Modify(spec))
+
+			var timeouts []*int
+			for _, h := range spec.Hooks.CreateContainer {
+				timeouts = append(timeouts, h.Timeout)
+			}
+			require.Equal(t, tc.expectedTimeouts, timeouts)
+		})
+	}
+}
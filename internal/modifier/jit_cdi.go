@@ -0,0 +1,116 @@
+package modifier
+
+import (
+	"fmt"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/requirements"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/xdxml"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/xdxcdi"
+)
+
+// NewJitCDIModifier creates an OCI spec modifier that generates the CDI edits for the devices
+// requested in the XDXCT_VISIBLE_DEVICES environment variable in process. This replaces the
+// invocation of the xdxct-container-cli in the legacy mode while keeping its user interface:
+// the injected driver files are filtered by XDXCT_DRIVER_CAPABILITIES and the XDXCT_REQUIRE_*
+// requirements are checked before any modifications are made.
+func NewJitCDIModifier(logger logger.Interface, cfg *config.Config, ociSpec oci.Spec) (oci.SpecModifier, error) {
+	rawSpec, err := ociSpec.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OCI spec: %v", err)
+	}
+
+	gpuImage, err := image.NewGPUImageFromSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := getJitCDIDevices(logger, cfg, ociSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		logger.Debugf("No devices requested; no modification required.")
+		return nil, nil
+	}
+
+	capabilities, err := getDriverCapabilities(cfg, gpuImage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRequirements(logger, cfg, gpuImage); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("Creating jit-cdi modifier for devices %v with capabilities %v", devices, capabilities)
+	return newAutomaticCDISpecModifier(logger, cfg, devices, xdxcdi.WithDriverCapabilities(capabilities))
+}
+
+// getJitCDIDevices returns the fully-qualified names of the devices requested by the container.
+// The devices are determined as for the cdi mode, but since the CDI spec is generated in process
+// only devices of the xdxct.com/gpu kind are supported.
+func getJitCDIDevices(logger logger.Interface, cfg *config.Config, ociSpec oci.Spec) ([]string, error) {
+	devices, err := getDevicesFromSpec(logger, ociSpec, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get required devices from OCI specification: %v", err)
+	}
+	if automatic := filterAutomaticDevices(devices); len(automatic) != len(devices) {
+		return nil, fmt.Errorf("only xdxct.com/gpu devices are supported in jit-cdi mode; requested %v", devices)
+	}
+	return devices, nil
+}
+
+// getDriverCapabilities returns the driver capabilities requested by the container. If no
+// capabilities are requested, the default capabilities are used for standard images and all
+// supported capabilities are used for legacy images.
+func getDriverCapabilities(cfg *config.Config, gpuImage image.GPU) (image.DriverCapabilities, error) {
+	supported := image.NewDriverCapabilities(cfg.SupportedDriverCapabilities)
+	if supported.IsAll() {
+		supported = image.SupportedDriverCapabilities
+	}
+
+	env := gpuImage.Getenv("XDXCT_DRIVER_CAPABILITIES")
+	if !gpuImage.HasEnvvar("XDXCT_DRIVER_CAPABILITIES") && gpuImage.IsLegacy() {
+		return supported, nil
+	}
+	if env == "" {
+		return supported.Intersection(image.DefaultDriverCapabilities), nil
+	}
+
+	requested := image.NewDriverCapabilities(env)
+	capabilities := supported.Intersection(requested)
+	if !requested.IsAll() && len(capabilities) != len(requested) {
+		return nil, fmt.Errorf("unsupported capabilities found in '%v' (allowed '%v')", requested, supported)
+	}
+	return capabilities, nil
+}
+
+// checkRequirements checks the XDXCT_REQUIRE_* requirements of the container against the properties of the host.
+func checkRequirements(logger logger.Interface, cfg *config.Config, gpuImage image.GPU) error {
+	if cfg.DisableRequire {
+		return nil
+	}
+	reqs, err := gpuImage.GetRequirements()
+	if err != nil {
+		return fmt.Errorf("failed to get requirements: %v", err)
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	properties, err := requirements.DetectHostProperties(logger, xdxml.New())
+	if err != nil {
+		return fmt.Errorf("failed to detect host properties: %v", err)
+	}
+
+	r := requirements.New(logger, reqs)
+	r.AddHostProperties(properties)
+	if err := r.Assert(); err != nil {
+		return fmt.Errorf("requirements not satisfied: %v", err)
+	}
+	return nil
+}
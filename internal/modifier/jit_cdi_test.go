@@ -0,0 +1,144 @@
+package modifier
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+func TestGetDriverCapabilities(t *testing.T) {
+	testCases := []struct {
+		description   string
+		env           []string
+		supported     string
+		expectedError bool
+		expected      string
+	}{
+		{
+			description: "default capabilities",
+			env:         []string{"XDXCT_VISIBLE_DEVICES=0"},
+			supported:   "all",
+			expected:    "compute,utility",
+		},
+		{
+			description: "empty envvar uses default capabilities",
+			env:         []string{"XDXCT_DRIVER_CAPABILITIES="},
+			supported:   "all",
+			expected:    "compute,utility",
+		},
+		{
+			description: "legacy image uses all supported capabilities",
+			env:         []string{"GPU_VERSION=1.0"},
+			supported:   "compute,graphics",
+			expected:    "compute,graphics",
+		},
+		{
+			description: "requested capabilities",
+			env:         []string{"XDXCT_DRIVER_CAPABILITIES=graphics,video"},
+			supported:   "all",
+			expected:    "graphics,video",
+		},
+		{
+			description: "all is filtered by supported capabilities",
+			env:         []string{"XDXCT_DRIVER_CAPABILITIES=all"},
+			supported:   "compute,utility",
+			expected:    "compute,utility",
+		},
+		{
+			description:   "unsupported capability is an error",
+			env:           []string{"XDXCT_DRIVER_CAPABILITIES=graphics"},
+			supported:     "compute,utility",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			gpuImage, err := image.NewGPUImageFromEnv(tc.env)
+			require.NoError(t, err)
+
+			cfg := &config.Config{SupportedDriverCapabilities: tc.supported}
+			capabilities, err := getDriverCapabilities(cfg, gpuImage)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, capabilities.String())
+		})
+	}
+}
+
+func TestGetJitCDIDevices(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testCases := []struct {
+		description                    string
+		env                            []string
+		mounts                         []specs.Mount
+		acceptDeviceListAsVolumeMounts bool
+		expectedError                  bool
+		expectedDevices                []string
+	}{
+		{
+			description:     "device indices",
+			env:             []string{"XDXCT_VISIBLE_DEVICES=0,1"},
+			expectedDevices: []string{"xdxct.com/gpu=0", "xdxct.com/gpu=1"},
+		},
+		{
+			description:     "fully-qualified names are not prefixed",
+			env:             []string{"XDXCT_VISIBLE_DEVICES=xdxct.com/gpu=0,xdxct.com/gpu=1"},
+			expectedDevices: []string{"xdxct.com/gpu=0", "xdxct.com/gpu=1"},
+		},
+		{
+			description:   "other device kinds are not supported",
+			env:           []string{"XDXCT_VISIBLE_DEVICES=example.com/device=0"},
+			expectedError: true,
+		},
+		{
+			description: "devices as volume mounts",
+			env:         []string{"XDXCT_VISIBLE_DEVICES=1"},
+			mounts: []specs.Mount{
+				{Source: "/dev/null", Destination: "/var/run/xdxct-container-devices/cdi/xdxct.com/gpu/0"},
+			},
+			acceptDeviceListAsVolumeMounts: true,
+			expectedDevices:                []string{"xdxct.com/gpu=0"},
+		},
+		{
+			description: "volume mounts are ignored if not accepted",
+			env:         []string{"XDXCT_VISIBLE_DEVICES=1"},
+			mounts: []specs.Mount{
+				{Source: "/dev/null", Destination: "/var/run/xdxct-container-devices/cdi/xdxct.com/gpu/0"},
+			},
+			expectedDevices: []string{"xdxct.com/gpu=1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg, err := config.GetDefault()
+			require.NoError(t, err)
+			cfg.AcceptDeviceListAsVolumeMounts = tc.acceptDeviceListAsVolumeMounts
+
+			ociSpec := &oci.SpecMock{
+				LoadFunc: func() (*specs.Spec, error) {
+					return &specs.Spec{Process: &specs.Process{Env: tc.env}, Mounts: tc.mounts}, nil
+				},
+			}
+
+			devices, err := getJitCDIDevices(logger, cfg, ociSpec)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDevices, devices)
+		})
+	}
+}
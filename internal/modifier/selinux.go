@@ -0,0 +1,244 @@
+package modifier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/opencontainers/selinux/go-selinux"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+// The following SELinux labeling modes are supported for injected mounts and device nodes.
+const (
+	selinuxLabelingDisabled = "disabled"
+	selinuxLabelingRelabel  = "relabel"
+)
+
+const (
+	selinuxfsRoot = "/sys/fs/selinux"
+)
+
+// selinuxLabels is a spec modifier that wraps another modifier and ensures that the mounts and
+// device nodes injected by the wrapped modifier are accessible to the container on hosts where
+// SELinux is enabled.
+type selinuxLabels struct {
+	logger   logger.Interface
+	modifier oci.SpecModifier
+	labeling string
+	// relabelPaths are the toolkit-owned host directories whose files may be relabelled.
+	relabelPaths []string
+}
+
+var _ oci.SpecModifier = (*selinuxLabels)(nil)
+
+// NewSELinuxModifier wraps the specified modifier so that the injected mounts are labelled with the
+// mount label of the container. With the relabel mode the injected files below the configured
+// toolkit-owned directories are relabelled on the host; driver files and device nodes are shared with
+// the host and are never relabelled. A context= mount option is not used since the kernel ignores it
+// for bind mounts. In all modes a warning is logged for the remaining injected paths that the
+// container process is not allowed to access.
+func NewSELinuxModifier(logger logger.Interface, cfg *config.Config, modifier oci.SpecModifier) (oci.SpecModifier, error) {
+	if modifier == nil {
+		return nil, nil
+	}
+
+	labeling := cfg.XDXCTContainerRuntimeConfig.SELinux.Labeling
+	switch labeling {
+	case "":
+		labeling = selinuxLabelingDisabled
+	case selinuxLabelingDisabled, selinuxLabelingRelabel:
+	default:
+		return nil, fmt.Errorf("invalid SELinux labeling mode %q", labeling)
+	}
+
+	m := selinuxLabels{
+		logger:       logger,
+		modifier:     modifier,
+		labeling:     labeling,
+		relabelPaths: cfg.XDXCTContainerRuntimeConfig.SELinux.RelabelPaths,
+	}
+	return &m, nil
+}
+
+// Modify applies the wrapped modifier and labels the injected mounts and device nodes.
+func (m selinuxLabels) Modify(spec *specs.Spec) error {
+	existingMounts := make(map[string]bool)
+	for _, mount := range spec.Mounts {
+		existingMounts[mount.Destination] = true
+	}
+	existingDevices := make(map[string]bool)
+	if spec.Linux != nil {
+		for _, d := range spec.Linux.Devices {
+			existingDevices[d.Path] = true
+		}
+	}
+
+	if err := m.modifier.Modify(spec); err != nil {
+		return err
+	}
+
+	if !selinux.GetEnabled() {
+		return nil
+	}
+	if spec.Linux == nil || spec.Linux.MountLabel == "" {
+		m.logger.Debugf("No SELinux mount label specified; skipping labeling of injected paths")
+		return nil
+	}
+	mountLabel := spec.Linux.MountLabel
+	var processLabel string
+	if spec.Process != nil {
+		processLabel = spec.Process.SelinuxLabel
+	}
+
+	for i := range spec.Mounts {
+		mount := &spec.Mounts[i]
+		if existingMounts[mount.Destination] {
+			continue
+		}
+		if m.labeling == selinuxLabelingRelabel && m.isToolkitOwned(mount.Source) && m.relabel(mount.Source, mountLabel) {
+			continue
+		}
+		m.checkAccess(processLabel, mount.Source)
+	}
+
+	for _, d := range spec.Linux.Devices {
+		if existingDevices[d.Path] {
+			continue
+		}
+		m.checkAccess(processLabel, d.Path)
+	}
+
+	return nil
+}
+
+// isToolkitOwned checks whether the specified host path is below one of the toolkit-owned directories.
+func (m selinuxLabels) isToolkitOwned(path string) bool {
+	path = filepath.Clean(path)
+	for _, dir := range m.relabelPaths {
+		dir = filepath.Clean(dir)
+		if dir == "/" || dir == "." {
+			continue
+		}
+		if strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// relabel relabels the specified host path with the shared variant of the specified label
+// and returns whether this was successful. Directories are not relabelled recursively.
+func (m selinuxLabels) relabel(path string, mountLabel string) bool {
+	m.logger.Debugf("Relabelling %v with SELinux label %v", path, mountLabel)
+	if err := selinux.Chcon(path, shareLabel(mountLabel), false); err != nil {
+		m.logger.Warningf("Failed to relabel %v: %v", path, err)
+		return false
+	}
+	return true
+}
+
+// checkAccess logs a warning if the specified process label is not allowed to read the specified path.
+func (m selinuxLabels) checkAccess(processLabel string, path string) {
+	if processLabel == "" {
+		return
+	}
+	fileLabel, err := selinux.FileLabel(path)
+	if err != nil {
+		m.logger.Debugf("Failed to get SELinux label for %v: %v", path, err)
+		return
+	}
+
+	allowed, err := canRead(processLabel, fileLabel, fileClass(path))
+	if err != nil {
+		m.logger.Debugf("Failed to check SELinux access for %v: %v", path, err)
+		return
+	}
+	if !allowed {
+		m.logger.Warningf("Container process label %v cannot access %v (labelled %v); consider enabling SELinux labeling in the config or disabling labeling for the container", processLabel, path, fileLabel)
+	}
+}
+
+// shareLabel returns the variant of the specified label without MCS categories so that the
+// relabelled file can be shared by multiple containers.
+func shareLabel(mountLabel string) string {
+	c, err := selinux.NewContext(mountLabel)
+	if err != nil {
+		return mountLabel
+	}
+	c["level"] = "s0"
+	return c.Get()
+}
+
+// fileClass returns the SELinux security class for the specified path.
+func fileClass(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "file"
+	}
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeCharDevice != 0:
+		return "chr_file"
+	case mode&os.ModeDevice != 0:
+		return "blk_file"
+	}
+	return "file"
+}
+
+// canRead queries the kernel security policy through selinuxfs to determine whether the source
+// context is allowed to read objects with the target context and the specified class.
+func canRead(source string, target string, class string) (bool, error) {
+	classIndex, err := readSelinuxfsInt(filepath.Join("class", class, "index"))
+	if err != nil {
+		return false, fmt.Errorf("failed to get index for class %v: %v", class, err)
+	}
+	permIndex, err := readSelinuxfsInt(filepath.Join("class", class, "perms", "read"))
+	if err != nil {
+		return false, fmt.Errorf("failed to get index for read permission of class %v: %v", class, err)
+	}
+	if permIndex < 1 || permIndex > 32 {
+		return false, fmt.Errorf("unexpected index %d for read permission of class %v", permIndex, class)
+	}
+
+	f, err := os.OpenFile(filepath.Join(selinuxfsRoot, "access"), os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s %s %d", source, target, classIndex); err != nil {
+		return false, fmt.Errorf("failed to query access: %v", err)
+	}
+	buf := make([]byte, 256)
+	n, err := f.Read(buf)
+	if err != nil {
+		return false, fmt.Errorf("failed to read access decision: %v", err)
+	}
+
+	// The decision is returned as: allowed decided auditallow auditdeny seqno flags
+	fields := strings.Fields(string(buf[:n]))
+	if len(fields) == 0 {
+		return false, fmt.Errorf("unexpected access decision %q", string(buf[:n]))
+	}
+	allowed, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return false, fmt.Errorf("unexpected access decision %q: %v", string(buf[:n]), err)
+	}
+	return allowed&(1<<(permIndex-1)) != 0, nil
+}
+
+func readSelinuxfsInt(path string) (int, error) {
+	contents, err := os.ReadFile(filepath.Join(selinuxfsRoot, path))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(contents)))
+}
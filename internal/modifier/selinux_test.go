@@ -0,0 +1,71 @@
+package modifier
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+)
+
+func TestNewSELinuxModifier(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+	inner := modifierFunc(func(*specs.Spec) error { return nil })
+
+	testCases := []struct {
+		labeling      string
+		expectedError bool
+	}{
+		{labeling: ""},
+		{labeling: "disabled"},
+		{labeling: "relabel"},
+		{labeling: "context", expectedError: true},
+		{labeling: "enforce", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.labeling, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.XDXCTContainerRuntimeConfig.SELinux.Labeling = tc.labeling
+
+			m, err := NewSELinuxModifier(logger, cfg, inner)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, m.Modify(&specs.Spec{}))
+		})
+	}
+
+	m, err := NewSELinuxModifier(logger, &config.Config{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, m)
+}
+
+func TestIsToolkitOwned(t *testing.T) {
+	m := selinuxLabels{
+		relabelPaths: []string{"/var/run/xdxct-container-devices", "/"},
+	}
+
+	testCases := map[string]bool{
+		"/var/run/xdxct-container-devices/all":     true,
+		"/var/run/xdxct-container-devices/../../x": false,
+		"/var/run/xdxct-container-devices":         false,
+		"/var/run/xdxct-container-devices-other/0": false,
+		"/usr/lib/x86_64-linux-gnu/libxdxml.so.1":  false,
+		"/dev/xdxgpu0": false,
+	}
+	for path, expected := range testCases {
+		t.Run(path, func(t *testing.T) {
+			require.Equal(t, expected, m.isToolkitOwned(path))
+		})
+	}
+}
+
+func TestShareLabel(t *testing.T) {
+	require.Equal(t, "system_u:object_r:container_file_t:s0", shareLabel("system_u:object_r:container_file_t:s0:c1,c2"))
+	require.Equal(t, "invalid", shareLabel("invalid"))
+}
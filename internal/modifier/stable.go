@@ -1,6 +1,7 @@
 package modifier
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
@@ -8,47 +9,142 @@ import (
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// The following lifecycles are supported for the XDXCT Container Runtime Hook.
+const (
+	hookLifecyclePrestart        = "prestart"
+	hookLifecycleCreateRuntime   = "createRuntime"
+	hookLifecycleCreateContainer = "createContainer"
+	hookLifecyclePoststart       = "poststart"
+	hookLifecyclePoststop        = "poststop"
+)
+
 // NewStableRuntimeModifier creates an OCI spec modifier that inserts the XDXCT Container Runtime Hook into an OCI
-// spec. The specified logger is used to capture log output.
-func NewStableRuntimeModifier(logger logger.Interface, xdxctContainerRuntimeHookPath string) oci.SpecModifier {
+// spec. The hook is inserted at the specified lifecycle with prestart being used if no lifecycle is specified.
+// The hook is also inserted as a poststop hook to clean up the container root filesystem and, if verifyDevices
+// is set, as a poststart hook to check that the requested devices are visible in the container.
+// The specified logger is used to capture log output.
+func NewStableRuntimeModifier(logger logger.Interface, xdxctContainerRuntimeHookPath string, lifecycle string, verifyDevices bool) oci.SpecModifier {
+	if lifecycle == "" {
+		lifecycle = hookLifecyclePrestart
+	}
 	m := stableRuntimeModifier{
-		logger:                         logger,
+		logger:                        logger,
 		xdxctContainerRuntimeHookPath: xdxctContainerRuntimeHookPath,
+		lifecycle:                     lifecycle,
+		verifyDevices:                 verifyDevices,
 	}
 
 	return &m
 }
 
-// stableRuntimeModifier modifies an OCI spec inplace, inserting the xdxct-container-runtime-hook as a
-// prestart hook. If the hook is already present, no modification is made.
+// stableRuntimeModifier modifies an OCI spec inplace, inserting the xdxct-container-runtime-hook at the
+// configured lifecycle. If the hook is already present, no modification is made.
 type stableRuntimeModifier struct {
-	logger                         logger.Interface
+	logger                        logger.Interface
 	xdxctContainerRuntimeHookPath string
+	lifecycle                     string
+	verifyDevices                 bool
 }
 
 // Modify applies the required modification to the incoming OCI spec, inserting the xdxct-container-runtime-hook
-// as a prestart hook.
+// at the configured lifecycle.
 func (m stableRuntimeModifier) Modify(spec *specs.Spec) error {
 	// If an XDXCT Container Runtime Hook already exists, we don't make any modifications to the spec.
 	if spec.Hooks != nil {
-		for _, hook := range spec.Hooks.Prestart {
-			if isXDXCTContainerRuntimeHook(&hook) {
-				m.logger.Infof("Existing xdxct prestart hook (%v) found in OCI spec", hook.Path)
-				return nil
+		for lifecycle, hooks := range runtimeHookLifecycles(spec.Hooks) {
+			for _, hook := range *hooks {
+				if isXDXCTContainerRuntimeHook(&hook) {
+					m.logger.Infof("Existing xdxct %v hook (%v) found in OCI spec", lifecycle, hook.Path)
+					return nil
+				}
 			}
 		}
 	}
 
+	if spec.Hooks == nil {
+		spec.Hooks = &specs.Hooks{}
+	}
+	hooks, ok := runtimeHookLifecycles(spec.Hooks)[m.lifecycle]
+	if !ok {
+		return fmt.Errorf("unsupported hook lifecycle %q", m.lifecycle)
+	}
+
 	path := m.xdxctContainerRuntimeHookPath
-	m.logger.Infof("Using prestart hook path: %v", path)
-	args := []string{filepath.Base(path)}
+	m.logger.Infof("Using %v hook path: %v", m.lifecycle, path)
+	*hooks = append(*hooks, m.hook(m.lifecycle))
+
+	if m.verifyDevices {
+		spec.Hooks.Poststart = append(spec.Hooks.Poststart, m.hook(hookLifecyclePoststart))
+	}
+	spec.Hooks.Poststop = append(spec.Hooks.Poststop, m.hook(hookLifecyclePoststop))
+
+	return nil
+}
+
+// hook returns the XDXCT Container Runtime Hook for the specified lifecycle.
+func (m stableRuntimeModifier) hook(lifecycle string) specs.Hook {
+	return runtimeHook(m.xdxctContainerRuntimeHookPath, lifecycle)
+}
+
+// NewPoststopModifier creates an OCI spec modifier that inserts the XDXCT Container Runtime Hook as
+// a poststop hook. This cleans up the files created in the container root filesystem by the hooks
+// injected in the CDI modes where the runtime hook is not otherwise inserted.
+func NewPoststopModifier(logger logger.Interface, xdxctContainerRuntimeHookPath string) oci.SpecModifier {
+	m := poststopModifier{
+		logger:                        logger,
+		xdxctContainerRuntimeHookPath: xdxctContainerRuntimeHookPath,
+	}
+	return &m
+}
+
+// poststopModifier modifies an OCI spec inplace, inserting the xdxct-container-runtime-hook as a
+// poststop hook. If the hook is already present as a poststop hook, no modification is made.
+type poststopModifier struct {
+	logger                        logger.Interface
+	xdxctContainerRuntimeHookPath string
+}
+
+// Modify applies the required modification to the incoming OCI spec, inserting the
+// xdxct-container-runtime-hook as a poststop hook.
+func (m poststopModifier) Modify(spec *specs.Spec) error {
 	if spec.Hooks == nil {
 		spec.Hooks = &specs.Hooks{}
 	}
-	spec.Hooks.Prestart = append(spec.Hooks.Prestart, specs.Hook{
-		Path: path,
-		Args: append(args, "prestart"),
-	})
+	for _, hook := range spec.Hooks.Poststop {
+		if isXDXCTContainerRuntimeHook(&hook) {
+			m.logger.Infof("Existing xdxct %v hook (%v) found in OCI spec", hookLifecyclePoststop, hook.Path)
+			return nil
+		}
+	}
 
+	m.logger.Infof("Using %v hook path: %v", hookLifecyclePoststop, m.xdxctContainerRuntimeHookPath)
+	spec.Hooks.Poststop = append(spec.Hooks.Poststop, runtimeHook(m.xdxctContainerRuntimeHookPath, hookLifecyclePoststop))
 	return nil
 }
+
+// runtimeHook returns the XDXCT Container Runtime Hook at the specified path for the specified lifecycle.
+func runtimeHook(path string, lifecycle string) specs.Hook {
+	return specs.Hook{
+		Path: path,
+		Args: []string{filepath.Base(path), lifecycle},
+	}
+}
+
+// runtimeHookLifecycles returns the hook lists in the specified hooks at which the
+// XDXCT Container Runtime Hook can be inserted.
+func runtimeHookLifecycles(hooks *specs.Hooks) map[string]*[]specs.Hook {
+	return map[string]*[]specs.Hook{
+		hookLifecyclePrestart:        &hooks.Prestart,
+		hookLifecycleCreateRuntime:   &hooks.CreateRuntime,
+		hookLifecycleCreateContainer: &hooks.CreateContainer,
+	}
+}
+
+// allRuntimeHookLifecycles returns the hook lists in the specified hooks that can contain the
+// XDXCT Container Runtime Hook, including the poststart and poststop hooks.
+func allRuntimeHookLifecycles(hooks *specs.Hooks) map[string]*[]specs.Hook {
+	lifecycles := runtimeHookLifecycles(hooks)
+	lifecycles[hookLifecyclePoststart] = &hooks.Poststart
+	lifecycles[hookLifecyclePoststop] = &hooks.Poststop
+	return lifecycles
+}
@@ -1,6 +1,7 @@
 package modifier
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -51,6 +52,8 @@ func TestAddHookModifier(t *testing.T) {
 
 	testCases := []struct {
 		description   string
+		lifecycle     string
+		verifyDevices bool
 		spec          specs.Spec
 		expectedError error
 		expectedSpec  specs.Spec
@@ -66,6 +69,12 @@ func TestAddHookModifier(t *testing.T) {
 							Args: []string{"xdxct-container-runtime-hook", "prestart"},
 						},
 					},
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
 				},
 			},
 		},
@@ -82,6 +91,12 @@ func TestAddHookModifier(t *testing.T) {
 							Args: []string{"xdxct-container-runtime-hook", "prestart"},
 						},
 					},
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
 				},
 			},
 		},
@@ -128,9 +143,115 @@ func TestAddHookModifier(t *testing.T) {
 							Args: []string{"xdxct-container-runtime-hook", "prestart"},
 						},
 					},
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "hook is added at createContainer",
+			lifecycle:   "createContainer",
+			spec:        specs.Spec{},
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{
+					CreateContainer: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "createContainer"},
+						},
+					},
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "hook is added at createRuntime",
+			lifecycle:   "createRuntime",
+			spec:        specs.Spec{},
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{
+					CreateRuntime: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "createRuntime"},
+						},
+					},
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
 				},
 			},
 		},
+		{
+			description: "existing prestart hook is detected for createContainer",
+			lifecycle:   "createContainer",
+			spec: specs.Spec{
+				Hooks: &specs.Hooks{
+					Prestart: []specs.Hook{
+						{
+							Path: "xdxct-container-runtime-hook",
+						},
+					},
+				},
+			},
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{
+					Prestart: []specs.Hook{
+						{
+							Path: "xdxct-container-runtime-hook",
+						},
+					},
+				},
+			},
+		},
+		{
+			description:   "poststart hook is added if devices are verified",
+			verifyDevices: true,
+			spec:          specs.Spec{},
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{
+					Prestart: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "prestart"},
+						},
+					},
+					Poststart: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststart"},
+						},
+					},
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
+				},
+			},
+		},
+		{
+			description:   "unsupported lifecycle is an error",
+			lifecycle:     "poststart",
+			spec:          specs.Spec{},
+			expectedError: fmt.Errorf("unsupported hook lifecycle"),
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -138,7 +259,7 @@ func TestAddHookModifier(t *testing.T) {
 
 		t.Run(tc.description, func(t *testing.T) {
 
-			m := NewStableRuntimeModifier(logger, testHookPath)
+			m := NewStableRuntimeModifier(logger, testHookPath, tc.lifecycle, tc.verifyDevices)
 
 			err := m.Modify(&tc.spec)
 			if tc.expectedError != nil {
@@ -152,3 +273,64 @@ func TestAddHookModifier(t *testing.T) {
 	}
 
 }
+
+func TestPoststopModifier(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	testHookPath := filepath.Join(cfg.binPath, "xdxct-container-runtime-hook")
+
+	testCases := []struct {
+		description  string
+		spec         specs.Spec
+		expectedSpec specs.Spec
+	}{
+		{
+			description: "poststop hook is added",
+			spec:        specs.Spec{},
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{
+					Poststop: []specs.Hook{
+						{
+							Path: testHookPath,
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "existing poststop hook is not duplicated",
+			spec: specs.Spec{
+				Hooks: &specs.Hooks{
+					Poststop: []specs.Hook{
+						{
+							Path: "/usr/bin/xdxct-container-runtime-hook",
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
+				},
+			},
+			expectedSpec: specs.Spec{
+				Hooks: &specs.Hooks{
+					Poststop: []specs.Hook{
+						{
+							Path: "/usr/bin/xdxct-container-runtime-hook",
+							Args: []string{"xdxct-container-runtime-hook", "poststop"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			m := NewPoststopModifier(logger, testHookPath)
+
+			err := m.Modify(&tc.spec)
+			require.NoError(t, err)
+
+			require.EqualValues(t, tc.expectedSpec, tc.spec)
+		})
+	}
+}
@@ -97,3 +97,121 @@ func HasCreateSubcommand(args []string) bool {
 
 	return false
 }
+
+// passthroughSubcommands are the low-level runtime subcommands that never require a modification
+// of the OCI spec.
+var passthroughSubcommands = map[string]bool{
+	"state":  true,
+	"kill":   true,
+	"delete": true,
+	"events": true,
+	"ps":     true,
+	"pause":  true,
+	"resume": true,
+	"list":   true,
+}
+
+// IsPassthroughSubcommand checks whether the supplied arguments represent a subcommand that
+// is forwarded to the low-level runtime without modification.
+func IsPassthroughSubcommand(args []string) bool {
+	if HasCreateSubcommand(args) {
+		return false
+	}
+	for _, a := range args {
+		if passthroughSubcommands[a] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDeleteSubcommand checks the supplied arguments for a 'delete' subcommand
+func HasDeleteSubcommand(args []string) bool {
+	if HasCreateSubcommand(args) {
+		return false
+	}
+	for _, a := range args {
+		if a == "delete" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetContainerID returns the container ID for a 'create' subcommand. This is the
+// last argument and an empty string is returned if it is not present.
+func GetContainerID(args []string) string {
+	if !HasCreateSubcommand(args) || len(args) == 0 {
+		return ""
+	}
+	id := args[len(args)-1]
+	if id == "create" || strings.HasPrefix(id, "-") {
+		return ""
+	}
+	return id
+}
+
+// globalValueFlags are the global flags of the low-level runtimes that take a value.
+var globalValueFlags = map[string]bool{
+	"root":           true,
+	"log":            true,
+	"log-format":     true,
+	"log-level":      true,
+	"criu":           true,
+	"rootless":       true,
+	"cgroup-manager": true,
+}
+
+// subcommandValueFlags are the flags of the low-level runtime subcommands that take a value.
+var subcommandValueFlags = map[string]map[string]bool{
+	"events": {"interval": true},
+	"ps":     {"format": true, "f": true},
+	"exec": {
+		"console-socket": true, "pid-file": true, "cwd": true, "env": true, "e": true,
+		"user": true, "u": true, "additional-gids": true, "g": true, "process": true,
+		"p": true, "apparmor": true, "process-label": true, "cap": true, "c": true,
+		"preserve-fds": true, "cgroup": true,
+	},
+	"update": {
+		"resources": true, "r": true, "blkio-weight": true, "cpu-period": true,
+		"cpu-quota": true, "cpu-share": true, "cpu-rt-period": true, "cpu-rt-runtime": true,
+		"cpuset-cpus": true, "cpuset-mems": true, "cpu-idle": true, "kernel-memory": true,
+		"kernel-memory-tcp": true, "memory": true, "memory-reservation": true,
+		"memory-swap": true, "pids-limit": true, "l3-cache-schema": true, "mem-bw-schema": true,
+	},
+}
+
+// GetSubcommandContainerID returns the container ID for the subcommand in the specified
+// arguments. This is the first positional argument of the subcommand after skipping the
+// program name, the global flags, and the flags of the subcommand. An empty string is
+// returned if no container ID is present.
+func GetSubcommandContainerID(args []string) string {
+	if HasCreateSubcommand(args) {
+		return GetContainerID(args)
+	}
+
+	var subcommand string
+	valueFlags := globalValueFlags
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if subcommand != "" && i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		}
+		if strings.HasPrefix(arg, "-") {
+			name := strings.TrimLeft(arg, "-")
+			if !strings.Contains(name, "=") && valueFlags[name] {
+				i++
+			}
+			continue
+		}
+		if subcommand != "" {
+			return arg
+		}
+		subcommand = arg
+		valueFlags = subcommandValueFlags[subcommand]
+	}
+	return ""
+}
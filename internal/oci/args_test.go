@@ -182,3 +182,104 @@ func TestHasCreateSubcommand(t *testing.T) {
 		require.Equal(t, tc.shouldModify, HasCreateSubcommand(tc.args), "%d: %v", i, tc)
 	}
 }
+
+func TestGetContainerID(t *testing.T) {
+	testCases := []struct {
+		args       []string
+		expectedID string
+	}{
+		{},
+		{
+			args: []string{"create"},
+		},
+		{
+			args:       []string{"create", "--bundle", "/bundle", "container-id"},
+			expectedID: "container-id",
+		},
+		{
+			args: []string{"create", "--bundle"},
+		},
+		{
+			args: []string{"start", "container-id"},
+		},
+	}
+
+	for i, tc := range testCases {
+		require.Equal(t, tc.expectedID, GetContainerID(tc.args), "%d: %v", i, tc)
+	}
+}
+
+func TestIsPassthroughSubcommand(t *testing.T) {
+	testCases := []struct {
+		args        []string
+		passthrough bool
+	}{
+		{},
+		{
+			args: []string{"runtime", "create", "--bundle", "/bundle", "kill"},
+		},
+		{
+			args:        []string{"runtime", "--root", "/run/runc", "state", "container-id"},
+			passthrough: true,
+		},
+		{
+			args:        []string{"runtime", "delete", "--force", "container-id"},
+			passthrough: true,
+		},
+		{
+			args: []string{"runtime", "start", "container-id"},
+		},
+	}
+
+	for i, tc := range testCases {
+		require.Equal(t, tc.passthrough, IsPassthroughSubcommand(tc.args), "%d: %v", i, tc)
+	}
+}
+
+func TestGetSubcommandContainerID(t *testing.T) {
+	testCases := []struct {
+		args       []string
+		expectedID string
+	}{
+		{},
+		{
+			args: []string{"runtime", "state"},
+		},
+		{
+			args:       []string{"runtime", "state", "container-id"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "--root", "/run/runc", "--log=/run/log.json", "kill", "container-id", "9"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "delete", "--force", "container-id"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "events", "--interval", "5s", "--stats", "container-id"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "ps", "--format", "json", "container-id", "--", "-ef"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "exec", "--cwd", "/tmp", "-e", "A=B", "--tty", "container-id", "ls", "-l"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "start", "--", "container-id"},
+			expectedID: "container-id",
+		},
+		{
+			args:       []string{"runtime", "--root", "/run/runc", "create", "--bundle", "/bundle", "container-id"},
+			expectedID: "container-id",
+		},
+	}
+
+	for i, tc := range testCases {
+		require.Equal(t, tc.expectedID, GetSubcommandContainerID(tc.args), "%d: %v", i, tc)
+	}
+}
@@ -11,15 +11,25 @@ import (
 // The executable specified is taken from the list of supplied candidates, with the first match
 // present in the PATH being selected. A logger is also specified.
 func NewLowLevelRuntime(logger logger.Interface, candidates []string) (Runtime, error) {
-	runtimePath, err := findRuntime(logger, candidates)
+	runtimePath, err := FindLowLevelRuntime(logger, candidates)
 	if err != nil {
-		return nil, fmt.Errorf("error locating runtime: %v", err)
+		return nil, err
 	}
 
 	logger.Infof("Using low-level runtime %v", runtimePath)
 	return NewRuntimeForPath(logger, runtimePath)
 }
 
+// FindLowLevelRuntime returns the path to the first of the supplied candidates that is present
+// in the PATH.
+func FindLowLevelRuntime(logger logger.Interface, candidates []string) (string, error) {
+	runtimePath, err := findRuntime(logger, candidates)
+	if err != nil {
+		return "", fmt.Errorf("error locating runtime: %v", err)
+	}
+	return runtimePath, nil
+}
+
 // findRuntime checks elements in a list of supplied candidates for a matching executable in the PATH.
 // The absolute path to the first match is returned.
 func findRuntime(logger logger.Interface, candidates []string) (string, error) {
@@ -13,6 +13,7 @@ import (
 type pathRuntime struct {
 	logger      logger.Interface
 	path        string
+	args        []string
 	execRuntime Runtime
 }
 
@@ -20,6 +21,12 @@ var _ Runtime = (*pathRuntime)(nil)
 
 // NewRuntimeForPath creates a Runtime for the specified logger and path
 func NewRuntimeForPath(logger logger.Interface, path string) (Runtime, error) {
+	return NewRuntimeForPathWithArgs(logger, path, nil)
+}
+
+// NewRuntimeForPathWithArgs creates a Runtime for the specified logger and path. The specified
+// arguments are inserted before the forwarded arguments when the runtime is executed.
+func NewRuntimeForPathWithArgs(logger logger.Interface, path string, args []string) (Runtime, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("invalid path '%v': %v", path, err)
@@ -31,6 +38,7 @@ func NewRuntimeForPath(logger logger.Interface, path string) (Runtime, error) {
 	shim := pathRuntime{
 		logger:      logger,
 		path:        path,
+		args:        args,
 		execRuntime: syscallExec{},
 	}
 
@@ -38,9 +46,10 @@ func NewRuntimeForPath(logger logger.Interface, path string) (Runtime, error) {
 }
 
 // Exec exces into the binary at the path from the pathRuntime struct, passing it the supplied arguments
-// after ensuring that the first argument is the path of the target binary.
+// after ensuring that the first argument is the path of the target binary. The additional arguments
+// of the runtime are inserted after the path.
 func (s pathRuntime) Exec(args []string) error {
-	runtimeArgs := []string{s.path}
+	runtimeArgs := append([]string{s.path}, s.args...)
 	if len(args) > 1 {
 		runtimeArgs = append(runtimeArgs, args[1:]...)
 	}
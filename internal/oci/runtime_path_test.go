@@ -82,3 +82,24 @@ func TestPathRuntimeForwardsArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestPathRuntimeInsertsArgs(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	mockedRuntime := &RuntimeMock{
+		ExecFunc: func(strings []string) error {
+			return nil
+		},
+	}
+	r := pathRuntime{
+		logger:      logger,
+		path:        "runtime",
+		args:        []string{"--systemd-cgroup"},
+		execRuntime: mockedRuntime,
+	}
+	require.NoError(t, r.Exec([]string{"shouldBeReplaced", "state", "container-id"}))
+
+	calls := mockedRuntime.ExecCalls()
+	require.Len(t, calls, 1)
+	require.Equal(t, []string{"runtime", "--systemd-cgroup", "state", "container-id"}, calls[0].Strings)
+}
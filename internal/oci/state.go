@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
 )
 
 // State stores an OCI container state. This includes the spec path and the environment
@@ -75,3 +76,11 @@ func (s *State) GetContainerRoot() (string, error) {
 
 	return filepath.Join(s.Bundle, containerRoot), nil
 }
+
+// LogFields returns the fields that identify the container in log entries.
+func (s *State) LogFields() logrus.Fields {
+	return logrus.Fields{
+		"container-id": s.ID,
+		"bundle":       s.Bundle,
+	}
+}
@@ -3,7 +3,10 @@ package requirements
 // A list of supported requirements / properties
 const (
 	ARCH   = "arch"
-	BRAND  = "brand"
-	GPU   = "gpu"
+	GPU    = "gpu"
 	DRIVER = "driver"
+	COUNT  = "count"
+	MEMORY = "memory"
+	PCIID  = "pciid"
+	KERNEL = "kernel"
 )
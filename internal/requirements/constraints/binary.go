@@ -36,6 +36,20 @@ func (c binary) eval() (bool, error) {
 		return true, nil
 	}
 
+	if m, ok := c.left.(matcher); ok && isGlob(c.right) {
+		matches, err := m.Matches(c.right)
+		if err != nil {
+			return false, err
+		}
+		switch c.operator {
+		case equal:
+			return matches, nil
+		case notEqual:
+			return !matches, nil
+		}
+		return false, fmt.Errorf("invalid operator %v for pattern %v", c.operator, c.right)
+	}
+
 	compare, err := c.left.CompareTo(c.right)
 	if err != nil {
 		return false, err
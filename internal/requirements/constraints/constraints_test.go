@@ -1 +1,139 @@
 package constraints
+
+import (
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintsFromRequirements(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	properties := map[string]Property{
+		"arch":   NewStringProperty("arch", "X2-200"),
+		"driver": NewVersionProperty("driver", "1.2.3"),
+		"count":  NewIntProperty("count", 2),
+		"memory": NewSizeProperty("memory", 16<<30),
+		"kernel": NewVersionProperty("kernel", "5.15.0"),
+	}
+
+	testCases := []struct {
+		requirement   string
+		expectedError bool
+		expected      bool
+	}{
+		{requirement: "driver>=1.0", expected: true},
+		{requirement: "driver<1.0", expected: false},
+		{requirement: "driver!=1.2.3", expected: false},
+		{requirement: "driver>=2.0 count=2", expected: true},
+		{requirement: "driver>=2.0 || count=2", expected: true},
+		{requirement: "driver>=1.0,count=3", expected: false},
+		{requirement: "driver>=1.0 && count>=2", expected: true},
+		{requirement: "!count=1", expected: true},
+		{requirement: "!(driver>=1.0,count=2)", expected: false},
+		{requirement: "(driver>=2.0 kernel>=5.4),count>1", expected: true},
+		{requirement: "( driver>=2.0 || kernel>=6.0 ) && count>1", expected: false},
+		{requirement: "arch=X2*", expected: true},
+		{requirement: "arch!=X2*", expected: false},
+		{requirement: "arch=X3-?00", expected: false},
+		{requirement: "memory>=16G", expected: true},
+		{requirement: "memory>16GiB", expected: false},
+		{requirement: "memory>=8192M,memory<32G", expected: true},
+		{requirement: "unknown=foo", expected: true},
+		{requirement: "unknown=foo,count=3", expected: false},
+		{requirement: "(driver>=1.0", expectedError: true},
+		{requirement: "driver>=1.0)", expectedError: true},
+		{requirement: "driver>=1.0 &", expectedError: true},
+		{requirement: "driver>=1.0,", expectedError: true},
+		{requirement: "driver>=1.*", expectedError: true},
+		{requirement: "arch>X2*", expectedError: true},
+		{requirement: "count>=two", expectedError: true},
+		{requirement: "memory>=16X", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.requirement, func(t *testing.T) {
+			c, err := New(logger, []string{tc.requirement}, properties)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			err = c.Assert()
+			if tc.expected {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			require.Equal(t, tc.expected, Evaluate(c).Satisfied)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	properties := map[string]Property{
+		"driver": NewVersionProperty("driver", "1.2.3"),
+		"count":  NewIntProperty("count", 2),
+	}
+
+	c, err := New(logger, []string{"driver>=2.0 (count=2,!driver<1.0)"}, properties)
+	require.NoError(t, err)
+
+	expected := Result{
+		Constraint: "driver>=2.0||count=2&&!driver<1.0",
+		Satisfied:  true,
+		Children: []Result{
+			{Constraint: "driver>=2.0", Satisfied: false, Reason: "driver=1.2.3"},
+			{
+				Constraint: "count=2&&!driver<1.0",
+				Satisfied:  true,
+				Children: []Result{
+					{Constraint: "count=2", Satisfied: true, Reason: "count=2"},
+					{
+						Constraint: "!driver<1.0",
+						Satisfied:  true,
+						Children: []Result{
+							{Constraint: "driver<1.0", Satisfied: false, Reason: "driver=1.2.3"},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.EqualValues(t, expected, Evaluate(c))
+}
+
+func TestNewStrict(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	properties := map[string]Property{
+		"driver": NewVersionProperty("driver", "1.2.3"),
+	}
+
+	testCases := []struct {
+		requirement   string
+		expectedError bool
+	}{
+		{requirement: "driver>=1.0"},
+		{requirement: "drvier>=1.0", expectedError: true},
+		{requirement: "driver>=1.0 || cuda>=12", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.requirement, func(t *testing.T) {
+			_, err := NewStrict(logger, []string{tc.requirement}, properties)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, err = New(logger, []string{tc.requirement}, properties)
+			require.NoError(t, err)
+		})
+	}
+}
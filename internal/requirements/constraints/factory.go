@@ -10,19 +10,28 @@ import (
 type factory struct {
 	logger     logger.Interface
 	properties map[string]Property
+	// strict causes unsupported properties to be treated as an error instead of being ignored.
+	strict bool
 }
 
-// New creates a new constraint for the supplied requirements and properties
+// New creates a new constraint for the supplied requirements and properties.
+// Constraints on unsupported properties are ignored.
 func New(logger logger.Interface, requirements []string, properties map[string]Property) (Constraint, error) {
+	return newConstraint(factory{logger: logger, properties: properties}, requirements)
+}
+
+// NewStrict creates a new constraint for the supplied requirements and properties.
+// Unlike New, constraints on unsupported properties are treated as an error so that typos
+// in a requirement are not silently ignored.
+func NewStrict(logger logger.Interface, requirements []string, properties map[string]Property) (Constraint, error) {
+	return newConstraint(factory{logger: logger, properties: properties, strict: true}, requirements)
+}
+
+func newConstraint(f factory, requirements []string) (Constraint, error) {
 	if len(requirements) == 0 {
 		return &always{}, nil
 	}
 
-	f := factory{
-		logger:     logger,
-		properties: properties,
-	}
-
 	var constraints []Constraint
 	for _, r := range requirements {
 		c, err := f.newConstraintFromRequirement(r)
@@ -39,45 +48,33 @@ func New(logger logger.Interface, requirements []string, properties map[string]P
 }
 
 // newConstraintFromRequirement takes a requirement string and generates
-// the associated constraint(s). Invalid constraints are ignored.
-// Each requirement can consist of multiple constraints, with space-separated constraints being ORed
-// together and comma-separated constraints being ANDed together.
+// the associated constraint(s). Unsupported properties are ignored unless the factory is strict.
+// Each requirement can consist of multiple constraints, with space-separated (or ||-separated)
+// constraints being ORed together and comma-separated (or &&-separated) constraints being ANDed
+// together. Constraints can be grouped using parentheses and negated using !.
 func (r factory) newConstraintFromRequirement(requirement string) (Constraint, error) {
-	const (
-		orSeparator  = " "
-		andSeparator = ","
-	)
 	if strings.TrimSpace(requirement) == "" {
 		return nil, nil
 	}
 
-	var terms []Constraint
-	for _, term := range strings.Split(requirement, orSeparator) {
-		var factors []Constraint
-		for _, factor := range strings.Split(term, andSeparator) {
-			f, err := r.parse(factor)
-			if err != nil {
-				return nil, err
-			}
-			if f == nil {
-				r.logger.Debugf("Skipping unsupported constraint: %v", factor)
-				continue
-			}
-			factors = append(factors, f)
-		}
-
-		if len(factors) == 0 {
-			continue
-		}
+	tokens, err := tokenize(requirement)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requirement %q: %v", requirement, err)
+	}
 
-		if len(factors) == 1 {
-			terms = append(terms, factors[0])
-		} else {
-			terms = append(terms, and(factors))
-		}
+	p := parser{
+		factory: r,
+		tokens:  tokens,
+	}
+	c, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid requirement %q: %v", requirement, err)
+	}
+	if t := p.peek(); t != nil {
+		return nil, fmt.Errorf("invalid requirement %q: unexpected %q", requirement, t.value)
 	}
 
-	return OR(terms), nil
+	return c, nil
 }
 
 // parse constructs a constraint from the specified string.
@@ -106,6 +103,10 @@ func (r factory) parse(condition string) (Constraint, error) {
 
 	p, ok := r.properties[property]
 	if !ok || p == nil {
+		if r.strict {
+			return nil, fmt.Errorf("unsupported property %q", property)
+		}
+		r.logger.Debugf("Ignoring constraint on unsupported property %q", property)
 		return nil, nil
 	}
 
@@ -118,6 +119,10 @@ func (r factory) parse(condition string) (Constraint, error) {
 	}
 	value := strings.TrimPrefix(condition, op)
 
+	if isGlob(value) && op != equal && op != notEqual {
+		return nil, fmt.Errorf("invalid constraint: %v%v; patterns are only supported for %v and %v", property, condition, equal, notEqual)
+	}
+
 	c := binary{
 		left:     p,
 		right:    value,
@@ -11,6 +11,11 @@ type or []Constraint
 // and represents an AND (ALL) operation on a set of contraints
 type and []Constraint
 
+// not represents the negation of a constraint
+type not struct {
+	operand Constraint
+}
+
 // AND constructs a new constraint that is the logical AND of the supplied constraints
 func AND(constraints []Constraint) Constraint {
 	if len(constraints) == 0 {
@@ -34,6 +39,14 @@ func OR(constraints []Constraint) Constraint {
 	return or(constraints)
 }
 
+// NOT constructs a new constraint that is the logical negation of the supplied constraint
+func NOT(constraint Constraint) Constraint {
+	if constraint == nil {
+		return nil
+	}
+	return not{operand: constraint}
+}
+
 func (operands or) Assert() error {
 	for _, o := range operands {
 		// We stop on the first nil
@@ -68,8 +81,28 @@ func (operands and) String() string {
 	var terms []string
 
 	for _, o := range operands {
+		// Since AND binds tighter than OR, nested ORs need to be grouped.
+		if _, ok := o.(or); ok {
+			terms = append(terms, "("+o.String()+")")
+			continue
+		}
 		terms = append(terms, o.String())
 	}
 
 	return strings.Join(terms, "&&")
 }
+
+func (c not) Assert() error {
+	if err := c.operand.Assert(); err != nil {
+		return nil
+	}
+	return fmt.Errorf("unsatisfied condition: %v", c.String())
+}
+
+func (c not) String() string {
+	switch c.operand.(type) {
+	case and, or:
+		return "!(" + c.operand.String() + ")"
+	}
+	return "!" + c.operand.String()
+}
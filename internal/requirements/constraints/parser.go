@@ -0,0 +1,194 @@
+package constraints
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+// token represents a single lexical element of a requirement expression.
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// startsOperand indicates whether the token can start an operand.
+func (t token) startsOperand() bool {
+	return t.kind == tokenTerm || t.kind == tokenNot || t.kind == tokenOpen
+}
+
+// endsOperand indicates whether the token can end an operand.
+func (t token) endsOperand() bool {
+	return t.kind == tokenTerm || t.kind == tokenClose
+}
+
+// tokenize splits a requirement expression into tokens.
+// For backward compatibility, whitespace separating two operands is treated as
+// an OR operator and a comma as an AND operator. The operators || and && may
+// also be used explicitly.
+func tokenize(requirement string) ([]token, error) {
+	const termDelimiters = " \t(),&|"
+
+	var tokens []token
+	var sawSpace bool
+	emit := func(t token) {
+		if sawSpace && t.startsOperand() && len(tokens) > 0 && tokens[len(tokens)-1].endsOperand() {
+			tokens = append(tokens, token{kind: tokenOr, value: " "})
+		}
+		tokens = append(tokens, t)
+		sawSpace = false
+	}
+
+	for i := 0; i < len(requirement); {
+		switch c := requirement[i]; {
+		case c == ' ' || c == '\t':
+			sawSpace = true
+			i++
+		case c == '(':
+			emit(token{kind: tokenOpen, value: "("})
+			i++
+		case c == ')':
+			emit(token{kind: tokenClose, value: ")"})
+			i++
+		case c == ',':
+			emit(token{kind: tokenAnd, value: ","})
+			i++
+		case strings.HasPrefix(requirement[i:], "&&"):
+			emit(token{kind: tokenAnd, value: "&&"})
+			i += 2
+		case strings.HasPrefix(requirement[i:], "||"):
+			emit(token{kind: tokenOr, value: "||"})
+			i += 2
+		case c == '!':
+			emit(token{kind: tokenNot, value: "!"})
+			i++
+		case c == '&' || c == '|':
+			return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+		default:
+			end := strings.IndexAny(requirement[i:], termDelimiters)
+			if end == -1 {
+				end = len(requirement) - i
+			}
+			emit(token{kind: tokenTerm, value: requirement[i : i+end]})
+			i += end
+		}
+	}
+
+	return tokens, nil
+}
+
+// parser is a recursive descent parser for requirement expressions.
+// The following grammar is supported with ! binding tighter than AND and AND
+// binding tighter than OR:
+//
+//	expression := and { OR and }
+//	and        := unary { AND unary }
+//	unary      := NOT unary | '(' expression ')' | TERM
+type parser struct {
+	factory
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) next() *token {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+// parseExpression parses a complete expression. Terms that reference
+// unsupported properties are dropped, which means that the returned
+// constraint may be nil.
+func (p *parser) parseExpression() (Constraint, error) {
+	var terms []Constraint
+	for {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if term != nil {
+			terms = append(terms, term)
+		}
+		if t := p.peek(); t == nil || t.kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+	return OR(terms), nil
+}
+
+func (p *parser) parseAnd() (Constraint, error) {
+	var factors []Constraint
+	for {
+		factor, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if factor != nil {
+			factors = append(factors, factor)
+		}
+		if t := p.peek(); t == nil || t.kind != tokenAnd {
+			break
+		}
+		p.next()
+	}
+	if len(factors) == 0 {
+		return nil, nil
+	}
+	return AND(factors), nil
+}
+
+func (p *parser) parseUnary() (Constraint, error) {
+	t := p.next()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch t.kind {
+	case tokenNot:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NOT(operand), nil
+	case tokenOpen:
+		c, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing == nil || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return c, nil
+	case tokenTerm:
+		c, err := p.parse(t.value)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			p.logger.Debugf("Skipping unsupported constraint: %v", t.value)
+		}
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %q", t.value)
+}
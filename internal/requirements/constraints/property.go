@@ -2,6 +2,8 @@ package constraints
 
 import (
 	"fmt"
+	"path"
+	"strconv"
 	"strings"
 
 	"golang.org/x/mod/semver"
@@ -18,6 +20,11 @@ type Property interface {
 	Validate(string) error
 }
 
+// matcher is implemented by properties that support matching against glob patterns.
+type matcher interface {
+	Matches(string) (bool, error)
+}
+
 // NewStringProperty creates a string property based on the name-value pair
 func NewStringProperty(name string, value string) Property {
 	p := stringProperty{
@@ -40,6 +47,31 @@ func NewVersionProperty(name string, value string) Property {
 	return p
 }
 
+// NewIntProperty creates a property representing an integer based on the name-value pair
+func NewIntProperty(name string, value int) Property {
+	p := intProperty{
+		stringProperty: stringProperty{
+			name:  name,
+			value: strconv.Itoa(value),
+		},
+	}
+
+	return p
+}
+
+// NewSizeProperty creates a property representing a size in bytes based on the name-value pair.
+// Values that are compared to the property may include a binary unit suffix such as 16G or 512MiB.
+func NewSizeProperty(name string, value uint64) Property {
+	p := sizeProperty{
+		stringProperty: stringProperty{
+			name:  name,
+			value: strconv.FormatUint(value, 10),
+		},
+	}
+
+	return p
+}
+
 // stringProperty represents a property that is used to check requirements
 type stringProperty struct {
 	name  string
@@ -50,6 +82,14 @@ type versionProperty struct {
 	stringProperty
 }
 
+type intProperty struct {
+	stringProperty
+}
+
+type sizeProperty struct {
+	stringProperty
+}
+
 // Name returns a stringProperty's name
 func (p stringProperty) Name() string {
 	return p.name
@@ -75,8 +115,19 @@ func (p stringProperty) CompareTo(other string) (int, error) {
 	return 0, nil
 }
 
-// Validate returns nil for all input strings
-func (p stringProperty) Validate(string) error {
+// Matches checks whether the stringProperty's value matches the specified glob pattern
+func (p stringProperty) Matches(pattern string) (bool, error) {
+	return path.Match(pattern, p.value)
+}
+
+// Validate returns nil for all input strings that are not malformed glob patterns
+func (p stringProperty) Validate(value string) error {
+	if !isGlob(value) {
+		return nil
+	}
+	if _, err := path.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid pattern %v: %v", value, err)
+	}
 	return nil
 }
 
@@ -109,6 +160,95 @@ func (p versionProperty) Validate(value string) error {
 	return nil
 }
 
+// CompareTo compares two integers to each other
+func (p intProperty) CompareTo(other string) (int, error) {
+	if err := p.Validate(other); err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+	value, err := strconv.ParseInt(p.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+	o, _ := strconv.ParseInt(other, 10, 64)
+
+	return compareInts(value, o), nil
+}
+
+// Validate checks whether the supplied value is a valid integer
+func (p intProperty) Validate(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("invalid value %v; expected an integer", value)
+	}
+	return nil
+}
+
+// CompareTo compares two sizes to each other
+func (p sizeProperty) CompareTo(other string) (int, error) {
+	o, err := ParseSize(other)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+	value, err := strconv.ParseInt(p.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %v: %v", p.name, err)
+	}
+
+	return compareInts(value, o), nil
+}
+
+// Validate checks whether the supplied value is a valid size
+func (p sizeProperty) Validate(value string) error {
+	_, err := ParseSize(value)
+	return err
+}
+
+// ParseSize parses a size in bytes. Binary (power of 1024) unit suffixes of K,
+// M, G, and T are supported and may optionally be followed by B or iB.
+func ParseSize(size string) (int64, error) {
+	multipliers := map[string]int64{
+		"":  1,
+		"K": 1 << 10,
+		"M": 1 << 20,
+		"G": 1 << 30,
+		"T": 1 << 40,
+	}
+
+	s := strings.ToUpper(strings.TrimSpace(size))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	unitStart := strings.IndexFunc(s, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if unitStart == -1 {
+		unitStart = len(s)
+	}
+
+	multiplier, ok := multipliers[s[unitStart:]]
+	if !ok || unitStart == 0 {
+		return 0, fmt.Errorf("invalid size %v", size)
+	}
+	value, err := strconv.ParseInt(s[:unitStart], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %v: %v", size, err)
+	}
+
+	return value * multiplier, nil
+}
+
+func compareInts(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isGlob(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
 func ensurePrefix(s string, prefix string) string {
 	return prefix + strings.TrimPrefix(s, prefix)
 }
@@ -0,0 +1,63 @@
+package constraints
+
+// Result represents the outcome of evaluating a constraint.
+// For logical constraints the results of the operands are included as children.
+type Result struct {
+	// Constraint is the string representation of the evaluated constraint
+	Constraint string
+	// Satisfied indicates whether the constraint is met
+	Satisfied bool
+	// Reason provides additional information such as the actual value of the
+	// property that was checked or the error encountered
+	Reason   string
+	Children []Result
+}
+
+// Evaluate evaluates the specified constraint and returns the result of each
+// sub-expression. Unlike Assert, all operands of logical constraints are
+// evaluated so that the reason for a failure can be explained.
+func Evaluate(c Constraint) Result {
+	if c == nil {
+		return Evaluate(&always{})
+	}
+
+	r := Result{
+		Constraint: c.String(),
+	}
+
+	switch c := c.(type) {
+	case and:
+		r.Satisfied = true
+		for _, o := range c {
+			child := Evaluate(o)
+			r.Satisfied = r.Satisfied && child.Satisfied
+			r.Children = append(r.Children, child)
+		}
+	case or:
+		for _, o := range c {
+			child := Evaluate(o)
+			r.Satisfied = r.Satisfied || child.Satisfied
+			r.Children = append(r.Children, child)
+		}
+	case not:
+		child := Evaluate(c.operand)
+		r.Satisfied = !child.Satisfied
+		r.Children = append(r.Children, child)
+	case binary:
+		satisfied, err := c.eval()
+		r.Satisfied = satisfied && err == nil
+		if err != nil {
+			r.Reason = err.Error()
+		} else if c.left != nil {
+			r.Reason = c.left.String()
+		}
+	default:
+		err := c.Assert()
+		r.Satisfied = err == nil
+		if err != nil {
+			r.Reason = err.Error()
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,140 @@
+package requirements
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/info/gpus"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/device"
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/xdxml"
+)
+
+// HostProperties holds the values of the supported properties as detected on the host.
+type HostProperties struct {
+	Arch        string
+	Driver      string
+	Kernel      string
+	PCIDeviceID string
+	Count       int
+	// Memory is the memory size in bytes of the smallest detected device.
+	Memory uint64
+}
+
+// DetectHostProperties queries the host for the values of the supported properties.
+// Device-specific properties such as the architecture are taken from the first
+// device. Properties that cannot be determined are left empty; if XDXML cannot be
+// initialized only the driver and kernel versions are returned. If querying the devices
+// fails, the properties detected so far are returned along with the error.
+func DetectHostProperties(logger logger.Interface, xdxmllib xdxml.Interface) (*HostProperties, error) {
+	p := &HostProperties{}
+
+	if version, err := info.GetDriverVersion("/"); err != nil {
+		logger.Warningf("Failed to determine driver version: %v", err)
+	} else {
+		p.Driver = version
+	}
+
+	kernel, err := getKernelVersion()
+	if err != nil {
+		logger.Warningf("Failed to determine kernel version: %v", err)
+	}
+	p.Kernel = kernel
+
+	if r := xdxmllib.Init(); r != xdxml.SUCCESS {
+		logger.Warningf("Failed to initialize XDXML: %v; device properties are not detected", r)
+		return p, nil
+	}
+	defer func() {
+		if r := xdxmllib.Shutdown(); r != xdxml.SUCCESS {
+			logger.Warningf("failed to shutdown XDXML: %v", r)
+		}
+	}()
+
+	devicelib := device.New(device.WithXdxml(xdxmllib))
+	err = devicelib.VisitDevices(func(i int, d device.Device) error {
+		p.Count++
+
+		pciInfo, ret := d.GetPciInfo()
+		if ret != xdxml.SUCCESS {
+			return fmt.Errorf("error getting PCI info for device %d: %v", i, ret)
+		}
+		sysfsPath := filepath.Join("/sys/bus/pci/devices", gpus.GetBusID(pciInfo))
+
+		memory, err := readSysfsUint(filepath.Join(sysfsPath, "mem_info_vram_total"))
+		if err != nil {
+			logger.Debugf("Failed to determine memory size for device %d: %v", i, err)
+		} else if p.Memory == 0 || memory < p.Memory {
+			p.Memory = memory
+		}
+
+		if i > 0 {
+			return nil
+		}
+
+		arch, ret := d.GetArchitecture()
+		if ret != xdxml.SUCCESS {
+			return fmt.Errorf("error getting architecture for device %d: %v", i, ret)
+		}
+		p.Arch = strings.TrimRight(arch, "\x00")
+
+		deviceID, err := os.ReadFile(filepath.Join(sysfsPath, "device"))
+		if err != nil {
+			logger.Debugf("Failed to determine PCI device ID for device %d: %v", i, err)
+		} else {
+			p.PCIDeviceID = strings.TrimPrefix(strings.TrimSpace(string(deviceID)), "0x")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// AddHostProperties adds the specified host properties to the requirements.
+func (r *Requirements) AddHostProperties(p *HostProperties) {
+	r.AddStringProperty(ARCH, p.Arch)
+	r.AddStringProperty(PCIID, p.PCIDeviceID)
+	r.AddVersionProperty(DRIVER, p.Driver)
+	r.AddVersionProperty(KERNEL, p.Kernel)
+	r.AddIntProperty(COUNT, p.Count)
+	r.AddSizeProperty(MEMORY, p.Memory)
+}
+
+// getKernelVersion returns the version of the running kernel.
+// Only the leading numeric components are returned so that a release such as
+// 5.15.0-91-generic is treated as 5.15.0 and not as a pre-release version.
+func getKernelVersion() (string, error) {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return "", err
+	}
+	return normalizeKernelVersion(unix.ByteSliceToString(uts.Release[:])), nil
+}
+
+func normalizeKernelVersion(release string) string {
+	end := strings.IndexFunc(release, func(r rune) bool {
+		return r != '.' && (r < '0' || r > '9')
+	})
+	if end == -1 {
+		end = len(release)
+	}
+	return strings.TrimSuffix(release[:end], ".")
+}
+
+func readSysfsUint(path string) (uint64, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(contents)), 10, 64)
+}
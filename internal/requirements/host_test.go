@@ -0,0 +1,63 @@
+package requirements
+
+import (
+	"testing"
+
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/xdxml"
+)
+
+// failingXdxml is an XDXML implementation that cannot be initialized.
+type failingXdxml struct{}
+
+func (failingXdxml) DeviceGetCount() (int, xdxml.Return) { return 0, xdxml.ERROR_UNINITIALIZED }
+func (failingXdxml) DeviceGetHandleByIndex(int) (xdxml.Device, xdxml.Return) {
+	return nil, xdxml.ERROR_UNINITIALIZED
+}
+func (failingXdxml) Init() xdxml.Return     { return xdxml.ERROR_LIBRARY_NOT_FOUND }
+func (failingXdxml) Shutdown() xdxml.Return { return xdxml.SUCCESS }
+
+func TestDetectHostPropertiesWithoutXdxml(t *testing.T) {
+	logger, hook := testlog.NewNullLogger()
+
+	p, err := DetectHostProperties(logger, failingXdxml{})
+	require.NoError(t, err)
+	require.NotNil(t, p)
+
+	require.NotEmpty(t, p.Kernel)
+	require.Empty(t, p.Arch)
+	require.Zero(t, p.Count)
+	require.Contains(t, hook.LastEntry().Message, "Failed to initialize XDXML")
+}
+
+// failingDevicesXdxml is an XDXML implementation for which the devices cannot be queried.
+type failingDevicesXdxml struct {
+	failingXdxml
+}
+
+func (failingDevicesXdxml) Init() xdxml.Return { return xdxml.SUCCESS }
+
+func TestDetectHostPropertiesWithFailingDevices(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	p, err := DetectHostProperties(logger, failingDevicesXdxml{})
+	require.Error(t, err)
+	require.NotNil(t, p)
+	require.NotEmpty(t, p.Kernel)
+}
+
+func TestNormalizeKernelVersion(t *testing.T) {
+	testCases := map[string]string{
+		"5.15.0-91-generic":     "5.15.0",
+		"6.8.0":                 "6.8.0",
+		"4.18.0-513.el8.x86_64": "4.18.0",
+		"6.1.":                  "6.1",
+	}
+	for release, expected := range testCases {
+		t.Run(release, func(t *testing.T) {
+			require.Equal(t, expected, normalizeKernelVersion(release))
+		})
+	}
+}
@@ -19,10 +19,13 @@ func New(logger logger.Interface, requirements []string) *Requirements {
 		requirements: requirements,
 		properties: map[string]constraints.Property{
 			// Set up the supported properties. These are overridden with actual values.
-			GPU:   constraints.NewVersionProperty(GPU, ""),
+			GPU:    constraints.NewVersionProperty(GPU, ""),
 			ARCH:   constraints.NewVersionProperty(ARCH, ""),
 			DRIVER: constraints.NewVersionProperty(DRIVER, ""),
-			BRAND:  constraints.NewStringProperty(BRAND, ""),
+			KERNEL: constraints.NewVersionProperty(KERNEL, ""),
+			PCIID:  constraints.NewStringProperty(PCIID, ""),
+			COUNT:  constraints.NewIntProperty(COUNT, 0),
+			MEMORY: constraints.NewSizeProperty(MEMORY, 0),
 		},
 	}
 
@@ -39,6 +42,16 @@ func (r *Requirements) AddStringProperty(name string, value string) {
 	r.properties[name] = constraints.NewStringProperty(name, value)
 }
 
+// AddIntProperty adds the specified property (name, value pair) to the requirements
+func (r *Requirements) AddIntProperty(name string, value int) {
+	r.properties[name] = constraints.NewIntProperty(name, value)
+}
+
+// AddSizeProperty adds the specified property (name, value pair) to the requirements
+func (r *Requirements) AddSizeProperty(name string, value uint64) {
+	r.properties[name] = constraints.NewSizeProperty(name, value)
+}
+
 // Assert checks the specified requirements
 func (r Requirements) Assert() error {
 	if len(r.requirements) == 0 {
@@ -52,3 +65,18 @@ func (r Requirements) Assert() error {
 	}
 	return c.Assert()
 }
+
+// Evaluate checks the specified requirements and returns the result for each
+// requirement and its sub-expressions. In contrast to Assert, requirements on
+// unsupported properties are reported as an error.
+func (r Requirements) Evaluate() ([]constraints.Result, error) {
+	var results []constraints.Result
+	for _, requirement := range r.requirements {
+		c, err := constraints.NewStrict(r.logger, []string{requirement}, r.properties)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, constraints.Evaluate(c))
+	}
+	return results, nil
+}
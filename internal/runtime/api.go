@@ -1,5 +1,7 @@
 package runtime
 
+import "errors"
+
 type rt struct {
 	logger       *Logger
 	modeOverride string
@@ -31,3 +33,17 @@ func WithModeOverride(mode string) Option {
 		r.modeOverride = mode
 	}
 }
+
+// ExitCode returns the exit code for the specified error returned by Run. If the error was
+// caused by the low-level runtime exiting with an error, its exit code is returned so that
+// this is forwarded to the caller. Otherwise 1 is returned for a non-nil error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
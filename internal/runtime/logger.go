@@ -107,6 +107,21 @@ func (l *Logger) Update(filename string, logLevel string, argv []string) {
 	}
 }
 
+// AddHook adds the specified hook to the underlying logrus logger.
+func (l *Logger) AddHook(hook logrus.Hook) {
+	if ll, ok := l.Interface.(*logrus.Logger); ok {
+		ll.AddHook(hook)
+	}
+}
+
+// ReplaceHooks replaces the hooks of the underlying logrus logger and returns the previous hooks.
+func (l *Logger) ReplaceHooks(hooks logrus.LevelHooks) logrus.LevelHooks {
+	if ll, ok := l.Interface.(*logrus.Logger); ok {
+		return ll.ReplaceHooks(hooks)
+	}
+	return nil
+}
+
 // Reset closes the log file (if any) and resets the logger output to what it
 // was before UpdateLogger was called.
 func (l *Logger) Reset() error {
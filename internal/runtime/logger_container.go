@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	redactedValue = "<redacted>"
+)
+
+// UpdateForContainer raises the log level of the logger to the specified level and tees the
+// output to the specified file. The level is never lowered by this call.
+func (l *Logger) UpdateForContainer(filename string, logLevel string) error {
+	ll, ok := l.Interface.(*logrus.Logger)
+	if !ok {
+		return fmt.Errorf("unsupported logger type %T", l.Interface)
+	}
+
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log-level '%v'", logLevel)
+	}
+	if level > ll.GetLevel() {
+		ll.SetLevel(level)
+	}
+
+	logFile, err := createLogFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	if logFile == nil {
+		return nil
+	}
+	ll.SetOutput(io.MultiWriter(ll.Out, logFile))
+	l.logFiles = append(l.logFiles, logFile)
+
+	return nil
+}
+
+// RedactEnv ensures that the values of the specified environment variables are not included
+// in the log output, including the output to the journald or syslog targets. Occurrences of
+// KEY=VALUE in log messages and fields are replaced by KEY=<redacted>. This includes occurrences
+// in JSON documents, such as a serialized OCI spec, where the value may be escaped.
+func (l *Logger) RedactEnv(env []string) {
+	var oldnew []string
+	seen := make(map[string]bool)
+	for _, e := range env {
+		key, value, found := strings.Cut(e, "=")
+		if !found || value == "" {
+			continue
+		}
+		for _, escape := range jsonEscapes {
+			old := escape(e)
+			if seen[old] {
+				continue
+			}
+			seen[old] = true
+			oldnew = append(oldnew, old, escape(key)+"="+redactedValue)
+		}
+	}
+	if len(oldnew) == 0 {
+		return
+	}
+
+	logger.AddHook(l, &redactingHook{
+		replacer: strings.NewReplacer(oldnew...),
+	})
+}
+
+// jsonEscapes are the forms in which an environment variable can appear in log output. Apart
+// from the literal form, this includes the escaped forms in a JSON string, with and without
+// HTML escaping, and in a JSON string that is itself embedded in a JSON string.
+var jsonEscapes = []func(string) string{
+	func(s string) string { return s },
+	func(s string) string { return jsonEscape(s, true) },
+	func(s string) string { return jsonEscape(s, false) },
+	func(s string) string { return jsonEscape(jsonEscape(s, true), true) },
+	func(s string) string { return jsonEscape(jsonEscape(s, false), false) },
+}
+
+// jsonEscape returns the specified string as it appears inside a JSON string.
+func jsonEscape(s string, escapeHTML bool) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(escapeHTML)
+	if err := encoder.Encode(s); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSuffix(b.String(), "\n"), "\""), "\"")
+}
+
+// redactingHook redacts the configured strings from log entries. Since log targets are fired
+// after all other hooks and the entry is only formatted once all hooks have been fired, the
+// entries are redacted for all outputs.
+type redactingHook struct {
+	replacer *strings.Replacer
+}
+
+// Levels returns the levels for which the hook fires.
+func (h *redactingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the message and fields of the entry.
+func (h *redactingHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.replacer.Replace(entry.Message)
+	for k, v := range entry.Data {
+		entry.Data[k] = h.redactValue(v)
+	}
+	return nil
+}
+
+// redactValue redacts the specified field value. Values that are not strings are redacted in
+// their formatted form and are only replaced if this contains a redacted string.
+func (h *redactingHook) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return h.replacer.Replace(v)
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = h.replacer.Replace(s)
+		}
+		return redacted
+	case error:
+		s := v.Error()
+		if r := h.replacer.Replace(s); r != s {
+			return r
+		}
+		return v
+	}
+	s := fmt.Sprint(v)
+	if r := h.replacer.Replace(s); r != s {
+		return r
+	}
+	return v
+}
+
+// getContainerLogFile returns the path to the log file for the specified container. An error
+// is returned if the container ID cannot be used as a file name.
+func getContainerLogFile(dir string, id string) (string, error) {
+	if !isValidContainerID(id) {
+		return "", fmt.Errorf("invalid container ID %q", id)
+	}
+	return filepath.Join(dir, id+".log"), nil
+}
@@ -0,0 +1,109 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateForContainer(t *testing.T) {
+	l := NewLogger()
+	l.Update("", "info", nil)
+
+	logFile := filepath.Join(t.TempDir(), "logs", "container.log")
+	require.NoError(t, l.UpdateForContainer(logFile, "debug"))
+	defer l.Reset()
+
+	ll := l.Interface.(*logrus.Logger)
+	require.Equal(t, logrus.DebugLevel, ll.Level)
+
+	l.Debugf("debug message")
+	contents, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "debug message")
+
+	require.NoError(t, l.UpdateForContainer("", "warning"))
+	require.Equal(t, logrus.DebugLevel, ll.Level)
+
+	require.Error(t, l.UpdateForContainer("", "verbose"))
+}
+
+func TestRedactEnv(t *testing.T) {
+	l := NewLogger()
+	ll := l.Interface.(*logrus.Logger)
+	buf := &bytes.Buffer{}
+	ll.SetOutput(buf)
+
+	l.RedactEnv([]string{"PASSWORD=secret", "EMPTY=", "XDXCT_VISIBLE_DEVICES=all"})
+	ll.WithField("env", "PASSWORD=secret").Infof("Process env: [PASSWORD=secret XDXCT_VISIBLE_DEVICES=all]")
+
+	require.NotContains(t, buf.String(), "secret")
+	require.Contains(t, buf.String(), "PASSWORD=<redacted>")
+	require.Contains(t, buf.String(), "XDXCT_VISIBLE_DEVICES=<redacted>")
+}
+
+func TestRedactEnvBeforeHooks(t *testing.T) {
+	l := NewLogger()
+	ll := l.Interface.(*logrus.Logger)
+	ll.SetOutput(io.Discard)
+
+	l.RedactEnv([]string{"PASSWORD=secret"})
+	hook := testlog.NewLocal(ll)
+	ll.WithField("env", []string{"PASSWORD=secret"}).Infof("Process env: [PASSWORD=secret]")
+
+	entry := hook.LastEntry()
+	require.Equal(t, "Process env: [PASSWORD=<redacted>]", entry.Message)
+	require.Equal(t, []string{"PASSWORD=<redacted>"}, entry.Data["env"])
+}
+
+func TestRedactEnvJSON(t *testing.T) {
+	l := NewLogger()
+	ll := l.Interface.(*logrus.Logger)
+	ll.SetFormatter(new(logrus.JSONFormatter))
+	buf := &bytes.Buffer{}
+	ll.SetOutput(buf)
+
+	env := []string{"PASSWORD=secret", `TOKEN=a"<b>\\c`}
+	l.RedactEnv(env)
+
+	spec, err := json.Marshal(specs.Spec{Process: &specs.Process{Env: env}})
+	require.NoError(t, err)
+	embedded, err := json.Marshal(map[string]string{"spec": string(spec)})
+	require.NoError(t, err)
+
+	ll.WithFields(logrus.Fields{
+		"env":   env,
+		"error": fmt.Errorf("invalid env %v", env),
+		"spec":  string(spec),
+	}).Infof("Loaded spec %s from %s", spec, embedded)
+
+	require.NotContains(t, buf.String(), "secret")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.NotContains(t, entry["msg"], "<b>")
+	require.NotContains(t, entry["msg"], "u003cb")
+	require.Equal(t, []interface{}{"PASSWORD=<redacted>", "TOKEN=<redacted>"}, entry["env"])
+	require.Equal(t, "invalid env [PASSWORD=<redacted> TOKEN=<redacted>]", entry["error"])
+	require.Contains(t, entry["msg"], `\"PASSWORD=<redacted>\"`)
+}
+
+func TestGetContainerLogFile(t *testing.T) {
+	filename, err := getContainerLogFile("/var/log/xdxct-container-runtime", "abc")
+	require.NoError(t, err)
+	require.Equal(t, "/var/log/xdxct-container-runtime/abc.log", filename)
+
+	for _, id := range []string{"", ".", "..", "../abc", "a/b"} {
+		_, err := getContainerLogFile("/var/log/xdxct-container-runtime", id)
+		require.Error(t, err, id)
+	}
+}
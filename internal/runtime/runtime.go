@@ -7,7 +7,10 @@ import (
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/info"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
 	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
 )
 
 // Run is an entry point that allows for idiomatic handling of errors
@@ -20,6 +23,9 @@ func (r rt) Run(argv []string) (rerr error) {
 	}()
 
 	printVersion := hasVersionFlag(argv)
+	if !printVersion && oci.IsPassthroughSubcommand(argv) {
+		return r.execPassthrough(argv)
+	}
 	if printVersion {
 		fmt.Printf("%v version %v\n", "XDXCT Container Runtime", info.GetVersionString(fmt.Sprintf("spec: %v", specs.Version)))
 	}
@@ -45,6 +51,13 @@ func (r rt) Run(argv []string) (rerr error) {
 	if r.modeOverride != "" {
 		cfg.XDXCTContainerRuntimeConfig.Mode = r.modeOverride
 	}
+	logger.AddFields(r.logger, getLogFields(argv))
+	if err := logger.SetTarget(r.logger, cfg.XDXCTContainerRuntimeConfig.LogTarget, "xdxct-container-runtime"); err != nil {
+		r.logger.Warningf("Failed to set log target: %v", err)
+	}
+	if oci.HasCreateSubcommand(argv) {
+		r.updateContainerLogger(cfg, argv)
+	}
 	cfg.XDXCTCTKConfig.Path = config.ResolveXDXCTCTKPath(r.logger, cfg.XDXCTCTKConfig.Path)
 	cfg.XDXCTContainerRuntimeHookConfig.Path = config.ResolveXDXCTContainerRuntimeHookPath(r.logger, cfg.XDXCTContainerRuntimeHookConfig.Path)
 
@@ -68,6 +81,102 @@ func (r rt) Run(argv []string) (rerr error) {
 	return runtime.Exec(argv)
 }
 
+// execPassthrough execs the low-level runtime for subcommands that require no modification. If a
+// low-level runtime was recorded for the container this is used. Otherwise the path to the
+// default low-level runtime is cached to avoid processing the config for each invocation.
+func (r rt) execPassthrough(argv []string) error {
+	cache := runtimeCache{
+		dir:        defaultRuntimeCacheDir,
+		configFile: config.GetConfigFilePath(),
+	}
+
+	if r := cache.findContainerRuntime(argv); r != nil {
+		if oci.HasDeleteSubcommand(argv) {
+			return r.runAndRemove(cache, argv)
+		}
+		runtime, err := oci.NewRuntimeForPathWithArgs(&logger.NullLogger{}, r.Path, r.Args)
+		if err != nil {
+			return fmt.Errorf("error constructing low-level runtime: %v", err)
+		}
+		return runtime.Exec(argv)
+	}
+
+	runtimePath := cache.get()
+	if runtimePath == "" {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("error loading config: %v", err)
+		}
+		runtimePath, err = oci.FindLowLevelRuntime(&logger.NullLogger{}, cfg.XDXCTContainerRuntimeConfig.Runtimes)
+		if err != nil {
+			return fmt.Errorf("error constructing low-level runtime: %v", err)
+		}
+		if err := cache.set(runtimePath); err != nil {
+			r.logger.Debugf("Failed to cache low-level runtime path: %v", err)
+		}
+	}
+
+	runtime, err := oci.NewRuntimeForPath(&logger.NullLogger{}, runtimePath)
+	if err != nil {
+		return fmt.Errorf("error constructing low-level runtime: %v", err)
+	}
+	return runtime.Exec(argv)
+}
+
+// updateContainerLogger applies the per-container logging options requested through the
+// allow-listed annotations in the OCI spec. The env values of the container are redacted
+// from the log output.
+func (r rt) updateContainerLogger(cfg *config.Config, argv []string) {
+	spec, err := oci.NewFileSpec(oci.GetSpecFilePath(getBundleDir(argv))).Load()
+	if err != nil {
+		r.logger.Debugf("Failed to load OCI spec for container logging: %v", err)
+		return
+	}
+
+	loggingConfig := cfg.XDXCTContainerRuntimeConfig.ContainerLogging
+	for _, annotation := range loggingConfig.Annotations {
+		level, ok := spec.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		filename, err := getContainerLogFile(loggingConfig.Dir, oci.GetContainerID(argv))
+		if err != nil {
+			r.logger.Warningf("Ignoring annotation %v: %v", annotation, err)
+			break
+		}
+		if err := r.logger.UpdateForContainer(filename, level); err != nil {
+			r.logger.Warningf("Ignoring annotation %v: %v", annotation, err)
+		}
+		break
+	}
+
+	if spec.Process != nil {
+		r.logger.RedactEnv(spec.Process.Env)
+	}
+}
+
+// getLogFields returns the fields that are attached to all log entries of a runtime invocation.
+func getLogFields(argv []string) logrus.Fields {
+	fields := logrus.Fields{
+		"trace-id":     logger.NewTraceID(),
+		"container-id": oci.GetContainerID(argv),
+	}
+	if oci.HasCreateSubcommand(argv) {
+		fields["bundle"] = getBundleDir(argv)
+	}
+	return fields
+}
+
+// addModeLogField attaches the resolved mode to all subsequent log entries.
+func addModeLogField(l logger.Interface, mode string) {
+	logger.AddFields(l, logrus.Fields{"mode": mode})
+}
+
+func getBundleDir(argv []string) string {
+	bundleDir, _ := oci.GetBundleDir(argv)
+	return bundleDir
+}
+
 func (r rt) Errorf(format string, args ...interface{}) {
 	r.logger.Errorf(format, args...)
 }
@@ -0,0 +1,218 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+const (
+	defaultRuntimeCacheDir = "/run/xdxct-container-runtime"
+	lowLevelRuntimeFile    = "low-level-runtime"
+	containersDir          = "containers"
+)
+
+// runtimeCache caches the path to the low-level runtime so that pass-through subcommands
+// do not require the config to be processed. A cached path is considered stale if the
+// config file was modified after the cache was written. The low-level runtimes selected
+// for individual containers are also recorded.
+type runtimeCache struct {
+	dir        string
+	configFile string
+}
+
+// get returns the cached path to the low-level runtime or an empty string if no valid
+// entry is cached or the cached path is no longer an executable file.
+func (c runtimeCache) get() string {
+	cacheFile := filepath.Join(c.dir, lowLevelRuntimeFile)
+	cacheInfo, err := os.Stat(cacheFile)
+	if err != nil {
+		return ""
+	}
+	if configInfo, err := os.Stat(c.configFile); err == nil && !configInfo.ModTime().Before(cacheInfo.ModTime()) {
+		return ""
+	}
+
+	contents, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(string(contents))
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return ""
+	}
+	return path
+}
+
+// set updates the cached path to the low-level runtime.
+func (c runtimeCache) set(path string) error {
+	return writeFileAtomic(c.dir, lowLevelRuntimeFile, []byte(path+"\n"))
+}
+
+// writeFileAtomic writes the specified contents to a file in the specified directory by
+// renaming a temporary file. The directory is created if required.
+func writeFileAtomic(dir string, name string, contents []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(contents)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %v", err)
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to update cache file permissions: %v", err)
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, name))
+}
+
+// containerRuntime is the low-level runtime recorded for a container.
+type containerRuntime struct {
+	Path string   `json:"path"`
+	Args []string `json:"args,omitempty"`
+	// id is the ID of the container for which the runtime was recorded
+	id string
+}
+
+// findContainerRuntime returns the low-level runtime recorded for the container referenced by the
+// subcommand in the specified arguments.
+func (c runtimeCache) findContainerRuntime(argv []string) *containerRuntime {
+	id := oci.GetSubcommandContainerID(argv)
+	if !isValidContainerID(id) {
+		return nil
+	}
+	contents, err := os.ReadFile(filepath.Join(c.dir, containersDir, id))
+	if err != nil {
+		return nil
+	}
+	var r containerRuntime
+	if err := json.Unmarshal(contents, &r); err != nil || !filepath.IsAbs(r.Path) {
+		return nil
+	}
+	r.id = id
+	return &r
+}
+
+// setContainerRuntime records the low-level runtime for the specified container.
+func (c runtimeCache) setContainerRuntime(id string, r *containerRuntime) error {
+	if !isValidContainerID(id) {
+		return fmt.Errorf("invalid container ID %q", id)
+	}
+	contents, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(c.dir, containersDir), id, contents)
+}
+
+// removeContainerRuntime removes the low-level runtime recorded for the specified container.
+func (c runtimeCache) removeContainerRuntime(id string) error {
+	if !isValidContainerID(id) {
+		return nil
+	}
+	err := os.Remove(filepath.Join(c.dir, containersDir, id))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isValidContainerID checks whether the specified ID can be used as a file name.
+func isValidContainerID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`) && !strings.HasPrefix(id, "-")
+}
+
+// runAndRemove runs the recorded low-level runtime for a delete subcommand and removes the
+// record for the container if this succeeds. The runtime is run as a child process instead
+// of being exec'd to allow for the record to be removed.
+func (r *containerRuntime) runAndRemove(c runtimeCache, argv []string) error {
+	if err := r.run(argv); err != nil {
+		return err
+	}
+
+	if err := c.removeContainerRuntime(r.id); err != nil {
+		return fmt.Errorf("failed to remove recorded low-level runtime: %v", err)
+	}
+	return nil
+}
+
+// createOrRemove runs the recorded low-level runtime for a create subcommand and removes the
+// record for the container if the container is not created. As for runAndRemove, the runtime
+// is run as a child process.
+func (r *containerRuntime) createOrRemove(c runtimeCache, argv []string) error {
+	err := r.run(argv)
+	if err == nil {
+		return nil
+	}
+	if rerr := c.removeContainerRuntime(r.id); rerr != nil {
+		return fmt.Errorf("%w; failed to remove recorded low-level runtime: %v", err, rerr)
+	}
+	return err
+}
+
+// run runs the runtime as a child process with the specified arguments. The standard streams
+// and the file descriptors preserved with --preserve-fds are forwarded to the child. If the
+// runtime exits with an error, the returned error wraps an *exec.ExitError so that the exit
+// code can be forwarded.
+func (r *containerRuntime) run(argv []string) error {
+	args := append([]string{}, r.Args...)
+	if len(argv) > 1 {
+		args = append(args, argv[1:]...)
+	}
+	cmd := exec.Command(r.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	for i := 0; i < getPreservedFDs(argv); i++ {
+		cmd.ExtraFiles = append(cmd.ExtraFiles, os.NewFile(uintptr(3+i), fmt.Sprintf("fd%d", 3+i)))
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("low-level runtime failed: %w", err)
+	}
+	return nil
+}
+
+// getPreservedFDs returns the number of additional file descriptors that are passed to the
+// low-level runtime as specified by the --preserve-fds flag.
+func getPreservedFDs(argv []string) int {
+	for i, arg := range argv {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "preserve-fds" {
+			continue
+		}
+		if !hasValue && i+1 < len(argv) {
+			value = argv[i+1]
+		}
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+		return 0
+	}
+	return 0
+}
+
+// runtimeFunc allows a function to be used as an oci.Runtime.
+type runtimeFunc func([]string) error
+
+// Exec calls the function with the specified arguments.
+func (f runtimeFunc) Exec(args []string) error {
+	return f(args)
+}
@@ -0,0 +1,138 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeCache(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.toml")
+	c := runtimeCache{
+		dir:        filepath.Join(dir, "cache"),
+		configFile: configFile,
+	}
+
+	require.Equal(t, "", c.get())
+
+	runc := filepath.Join(dir, "runc")
+	require.NoError(t, os.WriteFile(runc, nil, 0755))
+
+	require.NoError(t, c.set(runc))
+	require.Equal(t, runc, c.get())
+
+	// A config file that was modified after the cache was written invalidates the entry.
+	require.NoError(t, os.WriteFile(configFile, nil, 0644))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(configFile, future, future))
+	require.Equal(t, "", c.get())
+
+	require.NoError(t, os.Chtimes(configFile, time.Unix(0, 0), time.Unix(0, 0)))
+	require.Equal(t, runc, c.get())
+
+	require.NoError(t, os.Remove(runc))
+	require.Equal(t, "", c.get())
+
+	require.NoError(t, c.set("runc"))
+	require.Equal(t, "", c.get())
+}
+
+func TestContainerRuntimeRecords(t *testing.T) {
+	c := runtimeCache{
+		dir: t.TempDir(),
+	}
+
+	require.Nil(t, c.findContainerRuntime([]string{"runtime", "state", "container-id"}))
+
+	require.Error(t, c.setContainerRuntime("../container-id", &containerRuntime{Path: "/usr/bin/crun"}))
+	require.NoError(t, c.setContainerRuntime("container-id", &containerRuntime{Path: "/usr/bin/crun", Args: []string{"--systemd-cgroup"}}))
+
+	r := c.findContainerRuntime([]string{"runtime", "--root", "/run/runc", "kill", "container-id", "9"})
+	require.NotNil(t, r)
+	require.Equal(t, "/usr/bin/crun", r.Path)
+	require.Equal(t, []string{"--systemd-cgroup"}, r.Args)
+	require.Equal(t, "container-id", r.id)
+
+	// Only the positional container ID of the subcommand is used.
+	require.Nil(t, c.findContainerRuntime([]string{"runtime", "--log", "container-id", "state", "other-id"}))
+	require.Nil(t, c.findContainerRuntime([]string{"runtime", "exec", "other-id", "container-id"}))
+
+	require.NoError(t, c.removeContainerRuntime("container-id"))
+	require.Nil(t, c.findContainerRuntime([]string{"runtime", "state", "container-id"}))
+	require.NoError(t, c.removeContainerRuntime("container-id"))
+}
+
+func TestContainerRuntimeRun(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "runtime")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nfor arg; do last=$arg; done\nexit $last\n"), 0755))
+
+	c := runtimeCache{
+		dir: filepath.Join(dir, "cache"),
+	}
+
+	testCases := []struct {
+		description      string
+		run              func(*containerRuntime, []string) error
+		exitCode         string
+		expectedExitCode int
+		expectedRecord   bool
+	}{
+		{
+			description: "successful delete removes record",
+			run: func(r *containerRuntime, argv []string) error {
+				return r.runAndRemove(c, argv)
+			},
+			exitCode: "0",
+		},
+		{
+			description: "failed delete keeps record and exit code",
+			run: func(r *containerRuntime, argv []string) error {
+				return r.runAndRemove(c, argv)
+			},
+			exitCode:         "3",
+			expectedExitCode: 3,
+			expectedRecord:   true,
+		},
+		{
+			description: "successful create keeps record",
+			run: func(r *containerRuntime, argv []string) error {
+				return r.createOrRemove(c, argv)
+			},
+			exitCode:       "0",
+			expectedRecord: true,
+		},
+		{
+			description: "failed create removes record",
+			run: func(r *containerRuntime, argv []string) error {
+				return r.createOrRemove(c, argv)
+			},
+			exitCode:         "2",
+			expectedExitCode: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := &containerRuntime{Path: script, id: "container-id"}
+			require.NoError(t, c.setContainerRuntime(r.id, r))
+
+			err := tc.run(r, []string{"runtime", tc.exitCode})
+			require.Equal(t, tc.expectedExitCode, ExitCode(err))
+
+			_, err = os.Stat(filepath.Join(c.dir, containersDir, r.id))
+			require.Equal(t, tc.expectedRecord, err == nil)
+		})
+	}
+}
+
+func TestGetPreservedFDs(t *testing.T) {
+	require.Equal(t, 0, getPreservedFDs([]string{"runtime", "create", "container-id"}))
+	require.Equal(t, 2, getPreservedFDs([]string{"runtime", "create", "--preserve-fds", "2", "container-id"}))
+	require.Equal(t, 1, getPreservedFDs([]string{"runtime", "create", "--preserve-fds=1", "container-id"}))
+	require.Equal(t, 0, getPreservedFDs([]string{"runtime", "create", "--preserve-fds", "x", "container-id"}))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/edits"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/info"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/modifier"
@@ -12,12 +13,11 @@ import (
 )
 
 func newXDXCTContainerRuntime(logger logger.Interface, cfg *config.Config, argv []string) (oci.Runtime, error) {
-	lowLevelRuntime, err := oci.NewLowLevelRuntime(logger, cfg.XDXCTContainerRuntimeConfig.Runtimes)
-	if err != nil {
-		return nil, fmt.Errorf("error constructing low-level runtime: %v", err)
-	}
-
 	if !oci.HasCreateSubcommand(argv) {
+		lowLevelRuntime, err := newLowLevelRuntime(logger, cfg, argv, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing low-level runtime: %v", err)
+		}
 		logger.Debugf("Skipping modifier for non-create subcommand")
 		return lowLevelRuntime, nil
 	}
@@ -27,7 +27,22 @@ func newXDXCTContainerRuntime(logger logger.Interface, cfg *config.Config, argv
 		return nil, fmt.Errorf("error constructing OCI specification: %v", err)
 	}
 
-	specModifier, err := newSpecModifier(logger, cfg, ociSpec)
+	rawSpec, err := ociSpec.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OCI spec: %v", err)
+	}
+
+	lowLevelRuntime, err := newLowLevelRuntime(logger, cfg, argv, rawSpec)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing low-level runtime: %v", err)
+	}
+
+	bundleDir, err := oci.GetBundleDir(argv)
+	if err != nil {
+		return nil, err
+	}
+
+	specModifier, err := newSpecModifier(logger, cfg, ociSpec, bundleDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct OCI spec modifier: %v", err)
 	}
@@ -44,7 +59,7 @@ func newXDXCTContainerRuntime(logger logger.Interface, cfg *config.Config, argv
 }
 
 // newSpecModifier is a factory method that creates constructs an OCI spec modifer based on the provided config.
-func newSpecModifier(logger logger.Interface, cfg *config.Config, ociSpec oci.Spec) (oci.SpecModifier, error) {
+func newSpecModifier(logger logger.Interface, cfg *config.Config, ociSpec oci.Spec, bundleDir string) (oci.SpecModifier, error) {
 	rawSpec, err := ociSpec.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load OCI spec: %v", err)
@@ -55,17 +70,50 @@ func newSpecModifier(logger logger.Interface, cfg *config.Config, ociSpec oci.Sp
 		return nil, err
 	}
 
-	mode := info.ResolveAutoMode(logger, cfg.XDXCTContainerRuntimeConfig.Mode, image)
+	mode := info.ResolveAutoMode(
+		logger,
+		cfg.XDXCTContainerRuntimeConfig.Mode,
+		image,
+		info.WithAnnotations(rawSpec.Annotations),
+		info.WithCDIAnnotationPrefixes(cfg.XDXCTContainerRuntimeConfig.Modes.CDI.AnnotationPrefixes...),
+		info.WithSpecDirs(cfg.XDXCTContainerRuntimeConfig.Modes.CDI.SpecDirs...),
+		info.WithDefaultKind(cfg.XDXCTContainerRuntimeConfig.Modes.CDI.DefaultKind),
+	)
+	addModeLogField(logger, mode)
 	modeModifier, err := newModeModifier(logger, mode, cfg, ociSpec, image)
 	if err != nil {
 		return nil, err
 	}
-	// For CDI mode we make no additional modifications.
-	if mode == "cdi" {
-		return modeModifier, nil
+	gpuEnvModifier, err := modifier.NewGPUEnvModifier(logger, cfg, ociSpec)
+	if err != nil {
+		return nil, err
+	}
+	// For CDI modes we only add the poststop hook that cleans up the files created by the injected
+	// hooks. Note that the mount conflict policy is not applied since the CDI library always
+	// replaces existing mounts at the injected paths.
+	if mode == "cdi" || mode == "jit-cdi" {
+		if modeModifier == nil {
+			return nil, nil
+		}
+		poststopModifier := modifier.NewPoststopModifier(logger, cfg.XDXCTContainerRuntimeHookConfig.Path)
+		return newInjectedPathsModifier(logger, cfg, modifier.Merge(modeModifier, gpuEnvModifier, poststopModifier))
+	}
+
+	mountConflicts, err := edits.NewMountConflictPolicy(
+		cfg.XDXCTContainerRuntimeConfig.MountConflicts.DefaultPolicy,
+		cfg.XDXCTContainerRuntimeConfig.MountConflicts.Policies,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mount conflict policy: %v", err)
 	}
 
-	graphicsModifier, err := modifier.NewGraphicsModifier(logger, cfg, image)
+	graphicsModifier, err := modifier.NewGraphicsModifier(
+		logger,
+		cfg,
+		image,
+		edits.WithMountConflictPolicy(mountConflicts),
+		edits.WithBundleDir(bundleDir),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -73,19 +121,39 @@ func newSpecModifier(logger logger.Interface, cfg *config.Config, ociSpec oci.Sp
 	modifiers := modifier.Merge(
 		modeModifier,
 		graphicsModifier,
+		gpuEnvModifier,
 	)
-	return modifiers, nil
+	return newInjectedPathsModifier(logger, cfg, modifiers)
+}
+
+// newInjectedPathsModifier wraps the specified modifier with the modifiers that ensure that the
+// injected mounts and device nodes are accessible in the container and that the injected hooks
+// are run with the configured timeouts.
+func newInjectedPathsModifier(logger logger.Interface, cfg *config.Config, m oci.SpecModifier) (oci.SpecModifier, error) {
+	m, err := modifier.NewSELinuxModifier(logger, cfg, m)
+	if err != nil {
+		return nil, err
+	}
+	m = modifier.NewHookTimeoutsModifier(logger, cfg, m)
+	return modifier.NewDeviceCgroupRulesModifier(logger, cfg, m), nil
 }
 
 func newModeModifier(logger logger.Interface, mode string, cfg *config.Config, ociSpec oci.Spec, image image.GPU) (oci.SpecModifier, error) {
 	switch mode {
 	case "legacy":
-		return modifier.NewStableRuntimeModifier(logger, cfg.XDXCTContainerRuntimeHookConfig.Path), nil
+		return modifier.NewStableRuntimeModifier(
+			logger,
+			cfg.XDXCTContainerRuntimeHookConfig.Path,
+			cfg.XDXCTContainerRuntimeHookConfig.Lifecycle,
+			cfg.XDXCTContainerRuntimeHookConfig.VerifyDevices,
+		), nil
 	// CSV mode is to supports tegra device.
 	// case "csv":
 	// 	return modifier.NewCSVModifier(logger, cfg, image)
 	case "cdi":
 		return modifier.NewCDIModifier(logger, cfg, ociSpec)
+	case "jit-cdi":
+		return modifier.NewJitCDIModifier(logger, cfg, ociSpec)
 	}
 
 	return nil, fmt.Errorf("invalid runtime mode: %v", cfg.XDXCTContainerRuntimeConfig.Mode)
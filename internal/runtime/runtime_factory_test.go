@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	testlog "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+func TestNewSpecModifierAddsPoststopHookInCDIMode(t *testing.T) {
+	logger, _ := testlog.NewNullLogger()
+
+	specDir := t.TempDir()
+	cdiSpec := `cdiVersion: "0.5.0"
+kind: example.com/device
+devices:
+- name: dev0
+  containerEdits:
+    env:
+    - EXAMPLE_DEVICE=dev0
+`
+	require.NoError(t, os.WriteFile(filepath.Join(specDir, "example.yaml"), []byte(cdiSpec), 0644))
+
+	cfg, err := config.GetDefault()
+	require.NoError(t, err)
+	cfg.XDXCTContainerRuntimeConfig.Mode = "cdi"
+	cfg.XDXCTContainerRuntimeConfig.Modes.CDI.SpecDirs = []string{specDir}
+	cfg.XDXCTContainerRuntimeHookConfig.Path = "/usr/bin/xdxct-container-runtime-hook"
+
+	spec := &specs.Spec{
+		Process: &specs.Process{
+			Env: []string{"XDXCT_VISIBLE_DEVICES=example.com/device=dev0"},
+		},
+	}
+	ociSpec := &oci.SpecMock{
+		LoadFunc: func() (*specs.Spec, error) {
+			return spec, nil
+		},
+	}
+
+	m, err := newSpecModifier(logger, cfg, ociSpec, "")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.NoError(t, m.Modify(spec))
+
+	require.Contains(t, spec.Process.Env, "EXAMPLE_DEVICE=dev0")
+	require.NotNil(t, spec.Hooks)
+	require.Equal(t,
+		[]specs.Hook{
+			{
+				Path: "/usr/bin/xdxct-container-runtime-hook",
+				Args: []string{"xdxct-container-runtime-hook", "poststop"},
+			},
+		},
+		spec.Hooks.Poststop,
+	)
+	require.Empty(t, spec.Hooks.Prestart)
+}
@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
+	"github.com/XDXCT/xdxct-container-toolkit/internal/oci"
+)
+
+// newLowLevelRuntime constructs the low-level runtime for the specified arguments. For a create
+// subcommand the runtime is selected based on the annotations in the specified OCI spec and the
+// selection is recorded so that subsequent subcommands for the container use the same runtime.
+// If no runtime is selected, the first available runtime from the runtimes list is used. A
+// recorded runtime is run as a child process for the create subcommand so that the record is
+// removed if the container is not created.
+func newLowLevelRuntime(logger logger.Interface, cfg *config.Config, argv []string, spec *specs.Spec) (oci.Runtime, error) {
+	cache := runtimeCache{
+		dir: defaultRuntimeCacheDir,
+	}
+
+	if spec == nil {
+		if r := cache.findContainerRuntime(argv); r != nil {
+			logger.Infof("Using recorded low-level runtime %v %v", r.Path, r.Args)
+			return oci.NewRuntimeForPathWithArgs(logger, r.Path, r.Args)
+		}
+		return oci.NewLowLevelRuntime(logger, cfg.XDXCTContainerRuntimeConfig.Runtimes)
+	}
+
+	name, runtimeConfig := selectLowLevelRuntime(cfg, spec)
+	if runtimeConfig == nil {
+		return oci.NewLowLevelRuntime(logger, cfg.XDXCTContainerRuntimeConfig.Runtimes)
+	}
+
+	path, err := oci.FindLowLevelRuntime(logger, []string{runtimeConfig.Path})
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate low-level runtime %q: %v", name, err)
+	}
+	r := containerRuntime{
+		Path: path,
+		Args: runtimeConfig.Args,
+		id:   oci.GetContainerID(argv),
+	}
+	logger.Infof("Using low-level runtime %q: %v %v", name, r.Path, r.Args)
+	if err := cache.setContainerRuntime(r.id, &r); err != nil {
+		logger.Warningf("Failed to record low-level runtime for container: %v", err)
+		return oci.NewRuntimeForPathWithArgs(logger, r.Path, r.Args)
+	}
+
+	// The runtime is run as a child process so that the record is removed if the create fails.
+	return runtimeFunc(func(args []string) error {
+		return r.createOrRemove(cache, args)
+	}), nil
+}
+
+// selectLowLevelRuntime returns the name and config of the low-level runtime selected by the
+// annotations in the OCI spec. A nil config is returned if no configured runtime is selected.
+func selectLowLevelRuntime(cfg *config.Config, spec *specs.Spec) (string, *config.LowLevelRuntimeConfig) {
+	runtimes := cfg.XDXCTContainerRuntimeConfig.LowLevelRuntimes
+	for _, annotation := range runtimes.Annotations {
+		name, ok := spec.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		if r, ok := runtimes.Runtimes[name]; ok {
+			return name, &r
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+)
+
+func TestSelectLowLevelRuntime(t *testing.T) {
+	cfg, err := config.GetDefault()
+	require.NoError(t, err)
+	cfg.XDXCTContainerRuntimeConfig.LowLevelRuntimes.Runtimes = map[string]config.LowLevelRuntimeConfig{
+		"crun": {Path: "crun"},
+		"kata": {Path: "kata-runtime", Args: []string{"--log-format=json"}},
+	}
+
+	testCases := []struct {
+		description     string
+		annotations     map[string]string
+		expectedName    string
+		expectedRuntime *config.LowLevelRuntimeConfig
+	}{
+		{
+			description: "no annotations selects default",
+		},
+		{
+			description:     "explicit annotation",
+			annotations:     map[string]string{"xdxct.com/low-level-runtime": "crun"},
+			expectedName:    "crun",
+			expectedRuntime: &config.LowLevelRuntimeConfig{Path: "crun"},
+		},
+		{
+			description:     "runtime handler",
+			annotations:     map[string]string{"io.kubernetes.cri.runtime-handler": "kata"},
+			expectedName:    "kata",
+			expectedRuntime: &config.LowLevelRuntimeConfig{Path: "kata-runtime", Args: []string{"--log-format=json"}},
+		},
+		{
+			description: "unknown runtime handler selects default",
+			annotations: map[string]string{"io.kubernetes.cri.runtime-handler": "xdxct"},
+		},
+		{
+			description: "explicit annotation takes precedence",
+			annotations: map[string]string{
+				"xdxct.com/low-level-runtime":       "crun",
+				"io.kubernetes.cri.runtime-handler": "kata",
+			},
+			expectedName:    "crun",
+			expectedRuntime: &config.LowLevelRuntimeConfig{Path: "crun"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			name, r := selectLowLevelRuntime(cfg, &specs.Spec{Annotations: tc.annotations})
+			require.Equal(t, tc.expectedName, name)
+			require.Equal(t, tc.expectedRuntime, r)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package securejoin
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReplaceFile atomically replaces the file with the specified name in the directory. The
+// contents are written to a temporary file that is renamed once complete. The resulting file is
+// world readable for the cases where the container is run as a non-root user.
+func ReplaceFile(dir *os.File, name string, write func(io.Writer) error) error {
+	f, err := CreateTemp(dir, "."+name+"-", "")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tempName := filepath.Base(f.Name())
+	err = write(f)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if err == nil {
+		err = unix.Renameat(int(dir.Fd()), tempName, int(dir.Fd()), name)
+	}
+	if err != nil {
+		unix.Unlinkat(int(dir.Fd()), tempName, 0)
+		return err
+	}
+	return nil
+}
+
+// CreateTemp creates a new file with a random name in the specified directory. The file is
+// created relative to the opened directory and is not created if a file (or symlink) with the
+// same name exists.
+func CreateTemp(dir *os.File, prefix string, suffix string) (*os.File, error) {
+	for i := 0; i < 10000; i++ {
+		name := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10) + suffix
+		fd, err := unix.Openat(int(dir.Fd()), name, unix.O_RDWR|unix.O_CREAT|unix.O_EXCL|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0600)
+		if err == unix.EEXIST {
+			continue
+		}
+		if err != nil {
+			return nil, &os.PathError{Op: "openat", Path: filepath.Join(dir.Name(), name), Err: err}
+		}
+		return os.NewFile(uintptr(fd), filepath.Join(dir.Name(), name)), nil
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: filepath.Join(dir.Name(), prefix+"*"+suffix), Err: os.ErrExist}
+}
@@ -0,0 +1,177 @@
+package securejoin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	maxSymlinkDepth = 255
+)
+
+// ErrEscape is returned if a path refers to a location outside of the root.
+var ErrEscape = errors.New("path escapes the root")
+
+// openat2 allows the openat2 syscall to be overridden in tests.
+var openat2 = unix.Openat2
+
+// Resolve resolves the specified path relative to root and returns the resulting path on the
+// host. Symlinks in the path are followed with absolute link targets interpreted relative to
+// root. If a '..' component -- either in the path or in a link target -- would climb above
+// root, ErrEscape is returned. Components that do not exist are appended as is.
+func Resolve(root string, path string) (string, error) {
+	resolved, err := resolve(root, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, resolved), nil
+}
+
+// resolve returns the absolute path relative to root that the specified path resolves to.
+func resolve(root string, path string) (string, error) {
+	resolved := "/"
+	remaining := strings.Split(path, "/")
+	var linkCount int
+	for len(remaining) > 0 {
+		component := remaining[0]
+		remaining = remaining[1:]
+
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "/" {
+				return "", fmt.Errorf("%w: %v", ErrEscape, path)
+			}
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, component)
+		info, err := os.Lstat(filepath.Join(root, next))
+		if os.IsNotExist(err) {
+			resolved = next
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		linkCount++
+		if linkCount > maxSymlinkDepth {
+			return "", fmt.Errorf("too many levels of symbolic links resolving %v", path)
+		}
+		target, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			resolved = "/"
+		}
+		remaining = append(strings.Split(target, "/"), remaining...)
+	}
+
+	return resolved, nil
+}
+
+// OpenInRoot opens the specified path relative to root with the specified flags. The path is
+// resolved as for Resolve and opened using openat2 with RESOLVE_IN_ROOT so that symlinks
+// swapped in after resolution are not followed. If openat2 is not supported, the path is
+// opened one component at a time without following symlinks.
+func OpenInRoot(root string, path string, flags int) (*os.File, error) {
+	resolved, err := resolve(root, path)
+	if err != nil {
+		return nil, err
+	}
+	return openResolved(root, resolved, flags)
+}
+
+// openResolved opens a path relative to root that has already been resolved. Symlinks in the
+// path are not followed.
+func openResolved(root string, resolved string, flags int) (*os.File, error) {
+	rootFile, err := os.OpenFile(root, unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open root: %v", err)
+	}
+	defer rootFile.Close()
+
+	how := unix.OpenHow{
+		Flags:   uint64(flags | unix.O_CLOEXEC),
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_SYMLINKS | unix.RESOLVE_NO_MAGICLINKS,
+	}
+	fd, err := openat2(int(rootFile.Fd()), strings.TrimPrefix(resolved, "/"), &how)
+	if err == unix.ENOSYS {
+		return openNoFollow(rootFile, resolved, flags)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "openat2", Path: filepath.Join(root, resolved), Err: err}
+	}
+	return os.NewFile(uintptr(fd), filepath.Join(root, resolved)), nil
+}
+
+// openNoFollow opens the specified path relative to the root directory one component at a
+// time. Symlinks are not followed for any of the components.
+func openNoFollow(rootFile *os.File, path string, flags int) (*os.File, error) {
+	components := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	dir, err := unix.Openat(int(rootFile.Fd()), ".", unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i, component := range components {
+		if component == "" {
+			continue
+		}
+		componentFlags := unix.O_PATH | unix.O_DIRECTORY
+		if i == len(components)-1 {
+			componentFlags = flags
+		}
+		fd, err := unix.Openat(dir, component, componentFlags|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+		unix.Close(dir)
+		if err != nil {
+			return nil, &os.PathError{Op: "openat", Path: filepath.Join(rootFile.Name(), path), Err: err}
+		}
+		dir = fd
+	}
+	return os.NewFile(uintptr(dir), filepath.Join(rootFile.Name(), path)), nil
+}
+
+// MkdirAllInRoot creates the specified directory and any missing parents relative to root and
+// returns the opened directory. The path is resolved as for Resolve and directories are created
+// and opened one component at a time without following symlinks.
+func MkdirAllInRoot(root string, path string, perm os.FileMode) (*os.File, error) {
+	resolved, err := resolve(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := unix.Open(root, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open root: %v", err)
+	}
+	for _, component := range strings.Split(strings.TrimPrefix(resolved, "/"), "/") {
+		if component == "" {
+			continue
+		}
+		err := unix.Mkdirat(dir, component, uint32(perm.Perm()))
+		if err != nil && err != unix.EEXIST {
+			unix.Close(dir)
+			return nil, &os.PathError{Op: "mkdirat", Path: filepath.Join(root, resolved), Err: err}
+		}
+		fd, err := unix.Openat(dir, component, unix.O_PATH|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+		unix.Close(dir)
+		if err != nil {
+			return nil, &os.PathError{Op: "openat", Path: filepath.Join(root, resolved), Err: err}
+		}
+		dir = fd
+	}
+	return os.NewFile(uintptr(dir), filepath.Join(root, resolved)), nil
+}
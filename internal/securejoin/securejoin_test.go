@@ -0,0 +1,152 @@
+package securejoin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// createAdversarialRoot creates a root filesystem that includes symlinks that would point
+// outside of the root if followed on the host. A host directory is created next to the root.
+func createAdversarialRoot(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "rootfs")
+	host := filepath.Join(dir, "host")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr/lib64"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "dev"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(host, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "usr/lib64/libxdx.so"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(host, "etc/passwd"), nil, 0644))
+
+	require.NoError(t, os.Symlink("usr/lib64", filepath.Join(root, "lib64")))
+	require.NoError(t, os.Symlink("/etc", filepath.Join(root, "usr/absolute")))
+	require.NoError(t, os.Symlink(host, filepath.Join(root, "dev/dri")))
+	require.NoError(t, os.Symlink("../../host/etc", filepath.Join(root, "usr/escape")))
+	require.NoError(t, os.Symlink("loop", filepath.Join(root, "loop")))
+
+	return root, host
+}
+
+func TestResolve(t *testing.T) {
+	root, host := createAdversarialRoot(t)
+
+	testCases := []struct {
+		path          string
+		expected      string
+		expectedError error
+	}{
+		{path: "/usr/lib64/libxdx.so", expected: "/usr/lib64/libxdx.so"},
+		{path: "/lib64/libxdx.so", expected: "/usr/lib64/libxdx.so"},
+		{path: "/usr/absolute/passwd", expected: "/etc/passwd"},
+		{path: "/dev/dri/etc/passwd", expected: filepath.Join(host, "etc/passwd")},
+		{path: "/usr/../etc/passwd", expected: "/etc/passwd"},
+		{path: "/does/not/exist", expected: "/does/not/exist"},
+		{path: "/usr/escape/passwd", expectedError: ErrEscape},
+		{path: "/../../etc/passwd", expectedError: ErrEscape},
+		{path: "/does/not/../../../etc/passwd", expectedError: ErrEscape},
+		{path: "/loop/file", expectedError: unix.ELOOP},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			resolved, err := Resolve(root, tc.path)
+			if tc.expectedError == ErrEscape {
+				require.ErrorIs(t, err, ErrEscape)
+				return
+			}
+			if tc.expectedError != nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(root, tc.expected), resolved)
+		})
+	}
+}
+
+func TestOpenInRoot(t *testing.T) {
+	root, host := createAdversarialRoot(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, host, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, host, "etc/passwd"), []byte("container"), 0644))
+
+	withFallback := func(fallback bool) {
+		if !fallback {
+			openat2 = unix.Openat2
+			return
+		}
+		openat2 = func(int, string, *unix.OpenHow) (int, error) {
+			return -1, unix.ENOSYS
+		}
+	}
+	defer withFallback(false)
+
+	for _, fallback := range []bool{false, true} {
+		withFallback(fallback)
+
+		f, err := OpenInRoot(root, "/dev/dri/etc/passwd", os.O_RDONLY)
+		require.NoError(t, err)
+		contents, err := os.ReadFile(f.Name())
+		require.NoError(t, err)
+		require.Equal(t, "container", string(contents))
+		require.NoError(t, f.Close())
+
+		_, err = OpenInRoot(root, "/usr/escape/passwd", os.O_RDONLY)
+		require.ErrorIs(t, err, ErrEscape)
+
+		f, err = OpenInRoot(root, "/lib64/libxdx.so", os.O_RDONLY)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(root, "usr/lib64/libxdx.so"), f.Name())
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestOpenResolvedRefusesSymlinks(t *testing.T) {
+	root, host := createAdversarialRoot(t)
+
+	defer func() {
+		openat2 = unix.Openat2
+	}()
+	for _, fallback := range []bool{false, true} {
+		openat2 = unix.Openat2
+		if fallback {
+			openat2 = func(int, string, *unix.OpenHow) (int, error) {
+				return -1, unix.ENOSYS
+			}
+		}
+		// A symlink swapped in after the path was resolved must not be followed.
+		_, err := openResolved(root, "/dev/dri/etc/passwd", os.O_RDONLY)
+		require.Error(t, err)
+
+		_, err = openResolved(root, "/lib64/libxdx.so", os.O_RDONLY)
+		require.Error(t, err)
+
+		_, err = os.Stat(filepath.Join(host, "etc/passwd"))
+		require.NoError(t, err)
+	}
+}
+
+func TestMkdirAllInRoot(t *testing.T) {
+	root, host := createAdversarialRoot(t)
+
+	dir, err := MkdirAllInRoot(root, "/dev/dri/by-path", 0755)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, host, "by-path"), dir.Name())
+	require.NoError(t, unix.Symlinkat("../card0", int(dir.Fd()), "pci-0000:01:00.0-card"))
+	require.NoError(t, dir.Close())
+
+	_, err = os.Lstat(filepath.Join(root, host, "by-path/pci-0000:01:00.0-card"))
+	require.NoError(t, err)
+	_, err = os.Lstat(filepath.Join(host, "by-path"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = MkdirAllInRoot(root, "/usr/escape/ld.so.conf.d", 0755)
+	require.ErrorIs(t, err, ErrEscape)
+	_, err = os.Lstat(filepath.Join(host, "etc/ld.so.conf.d"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = MkdirAllInRoot(root, "/loop/dir", 0755)
+	require.Error(t, err)
+}
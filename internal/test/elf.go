@@ -0,0 +1,93 @@
+package test
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+)
+
+// WriteSharedLibrary writes a minimal ELF shared library with the specified class, machine,
+// processor-specific flags and SONAME. Only the sections required to read the SONAME and flags
+// are included.
+func WriteSharedLibrary(path string, class elf.Class, machine elf.Machine, flags uint32, soname string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	shstrtab := []byte("\x00.dynstr\x00.dynamic\x00.shstrtab\x00")
+	dynstr := []byte("\x00" + soname + "\x00")
+	var dynamic bytes.Buffer
+	var headerSize, sectionSize, dynSize int
+	writeDyn := func(tag elf.DynTag, val uint64) {
+		if class == elf.ELFCLASS64 {
+			binary.Write(&dynamic, binary.LittleEndian, elf.Dyn64{Tag: int64(tag), Val: val})
+		} else {
+			binary.Write(&dynamic, binary.LittleEndian, elf.Dyn32{Tag: int32(tag), Val: uint32(val)})
+		}
+	}
+	if soname != "" {
+		writeDyn(elf.DT_SONAME, 1)
+	}
+	writeDyn(elf.DT_NULL, 0)
+
+	if class == elf.ELFCLASS64 {
+		headerSize, sectionSize, dynSize = 64, 64, 16
+	} else {
+		headerSize, sectionSize, dynSize = 52, 40, 8
+	}
+	dynstrOffset := headerSize
+	dynamicOffset := dynstrOffset + len(dynstr)
+	shstrtabOffset := dynamicOffset + dynamic.Len()
+	sectionsOffset := shstrtabOffset + len(shstrtab)
+
+	type section struct {
+		name, typ, offset, size, link, entsize int
+	}
+	sections := []section{
+		{},
+		{name: 1, typ: int(elf.SHT_STRTAB), offset: dynstrOffset, size: len(dynstr)},
+		{name: 9, typ: int(elf.SHT_DYNAMIC), offset: dynamicOffset, size: dynamic.Len(), link: 1, entsize: dynSize},
+		{name: 18, typ: int(elf.SHT_STRTAB), offset: shstrtabOffset, size: len(shstrtab)},
+	}
+
+	var ident [elf.EI_NIDENT]byte
+	copy(ident[:], elf.ELFMAG)
+	ident[elf.EI_CLASS] = byte(class)
+	ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var contents bytes.Buffer
+	if class == elf.ELFCLASS64 {
+		binary.Write(&contents, binary.LittleEndian, elf.Header64{
+			Ident: ident, Type: uint16(elf.ET_DYN), Machine: uint16(machine), Version: uint32(elf.EV_CURRENT),
+			Flags: flags, Shoff: uint64(sectionsOffset), Ehsize: uint16(headerSize), Shentsize: uint16(sectionSize),
+			Shnum: uint16(len(sections)), Shstrndx: 3,
+		})
+	} else {
+		binary.Write(&contents, binary.LittleEndian, elf.Header32{
+			Ident: ident, Type: uint16(elf.ET_DYN), Machine: uint16(machine), Version: uint32(elf.EV_CURRENT),
+			Flags: flags, Shoff: uint32(sectionsOffset), Ehsize: uint16(headerSize), Shentsize: uint16(sectionSize),
+			Shnum: uint16(len(sections)), Shstrndx: 3,
+		})
+	}
+	contents.Write(dynstr)
+	contents.Write(dynamic.Bytes())
+	contents.Write(shstrtab)
+	for _, s := range sections {
+		if class == elf.ELFCLASS64 {
+			binary.Write(&contents, binary.LittleEndian, elf.Section64{
+				Name: uint32(s.name), Type: uint32(s.typ), Off: uint64(s.offset), Size: uint64(s.size),
+				Link: uint32(s.link), Addralign: 1, Entsize: uint64(s.entsize),
+			})
+		} else {
+			binary.Write(&contents, binary.LittleEndian, elf.Section32{
+				Name: uint32(s.name), Type: uint32(s.typ), Off: uint32(s.offset), Size: uint32(s.size),
+				Link: uint32(s.link), Addralign: 1, Entsize: uint32(s.entsize),
+			})
+		}
+	}
+
+	return os.WriteFile(path, contents.Bytes(), 0755)
+}
@@ -56,7 +56,7 @@ func DeviceGetUUID(Device Device) (string, Return) {
 	ret := xdxml_device_get_uuid(Device)
 	var uuidStr string
 	for _, num := range Device.Handle.uuid {
-		uuidStr += string(num)
+		uuidStr += string(rune(num))
 	}
 	return uuidStr, ret
 }
@@ -3,6 +3,7 @@ package xdxcdi
 import (
 	"fmt"
 
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/discover"
 )
 
@@ -17,20 +18,27 @@ func (l *xdxmllib) newCommonXDXMLDiscoverer() (discover.Discover, error) {
 	// 	},
 	// )
 
-	graphicsMounts, err := discover.NewGraphicsMountsDiscoverer(l.logger, l.driver, l.xdxctCTKPath)
-	if err != nil {
-		l.logger.Warningf("failed to create discoverer for graphics mounts: %v", err)
+	var graphicsMounts discover.Discover = discover.None{}
+	if l.driverCapabilities.Any(image.DriverCapabilityGraphics, image.DriverCapabilityDisplay) {
+		var err error
+		graphicsMounts, err = discover.NewGraphicsMountsDiscoverer(l.logger, l.driver, l.xdxctCTKPath)
+		if err != nil {
+			l.logger.Warningf("failed to create discoverer for graphics mounts: %v", err)
+		}
 	}
 
-	driverFiles, err := NewDriverDiscoverer(l.logger, l.driver, l.xdxctCTKPath, l.xdxmllib)
+	driverFiles, err := newDriverVersionDiscoverer(l.logger, l.driver, l.xdxctCTKPath, l.driverCapabilities, l.devSymlinks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create discoverer for driver files: %v", err)
 	}
 
+	icdFiles := discover.NewICDDiscoverer(l.logger, l.driver, l.xdxctCTKPath, driverFiles, l.driverCapabilities)
+
 	d := discover.Merge(
 		// pyMounts,
 		graphicsMounts,
 		driverFiles,
+		icdFiles,
 	)
 
 	return d, nil
@@ -3,6 +3,7 @@ package xdxcdi
 import (
 	"fmt"
 
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/discover"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup"
@@ -13,27 +14,33 @@ import (
 // NewDriverDiscoverer creates a discoverer for the libraries and binaries associated with a driver installation.
 // The supplied NVML Library is used to query the expected driver version.
 func NewDriverDiscoverer(logger logger.Interface, driver *root.Driver, xdxctCTKPath string, nvmllib xdxml.Interface) (discover.Discover, error) {
-	return newDriverVersionDiscoverer(logger, driver, xdxctCTKPath)
+	return newDriverVersionDiscoverer(logger, driver, xdxctCTKPath, image.NewDriverCapabilities("all"), false)
 }
 
-func newDriverVersionDiscoverer(logger logger.Interface, driver *root.Driver, xdxctCTKPath string) (discover.Discover, error) {
-	libraries, err := NewDriverLibraryDiscoverer(logger, driver, xdxctCTKPath)
+// newDriverVersionDiscoverer creates a discoverer for the driver files required by the specified driver capabilities.
+func newDriverVersionDiscoverer(logger logger.Interface, driver *root.Driver, xdxctCTKPath string, capabilities image.DriverCapabilities, devSymlinks bool) (discover.Discover, error) {
+	libraries, err := newDriverLibraryDiscoverer(logger, driver, xdxctCTKPath, capabilities, devSymlinks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create discoverer for driver libraries: %v", err)
 	}
 
-	binaries := NewDriverBinariesDiscoverer(logger, driver.Root)
-	xdxsmiPyDir := NewDriverPyDirDiscoverer(logger, driver.Root)
+	var binaries, xdxsmiPyDir discover.Discover = discover.None{}, discover.None{}
+	if capabilities.Has(image.DriverCapabilityUtility) {
+		binaries = NewDriverBinariesDiscoverer(logger, driver.Root)
+		xdxsmiPyDir = NewDriverPyDirDiscoverer(logger, driver.Root)
+	}
+
+	manifest := discover.NewManifestHook(logger, xdxctCTKPath, libraries, binaries)
 
 	d := discover.Merge(
 		libraries,
 		xdxsmiPyDir,
 		binaries,
+		manifest,
 	)
 
 	return d, nil
 }
-
 func NewDriverBinariesDiscoverer(logger logger.Interface, driverRoot string) discover.Discover {
 	return discover.NewMounts(
 		logger,
@@ -62,37 +69,70 @@ func NewDriverPyDirDiscoverer(logger logger.Interface, driverRoot string) discov
 	)
 }
 
+// driverLibrary associates a driver library with the driver capabilities that require it.
+type driverLibrary struct {
+	pattern      string
+	capabilities []image.DriverCapability
+}
+
+var (
+	graphicsCapabilities = []image.DriverCapability{image.DriverCapabilityGraphics, image.DriverCapabilityDisplay}
+	compilerCapabilities = append([]image.DriverCapability{image.DriverCapabilityCompute}, graphicsCapabilities...)
+	drmCapabilities      = append([]image.DriverCapability{image.DriverCapabilityUtility, image.DriverCapabilityVideo}, compilerCapabilities...)
+	videoCapabilities    = []image.DriverCapability{image.DriverCapabilityVideo}
+)
+
+// driverLibraries defines the libraries that are injected for a driver installation.
+var driverLibraries = []driverLibrary{
+	{"libxdxgpu-ml.so.*.*", []image.DriverCapability{image.DriverCapabilityUtility}},
+	{"libdrm.so", drmCapabilities},
+	{"libva-drm.so", videoCapabilities},
+	{"libva.so", videoCapabilities},
+	{"libva-x11.so", videoCapabilities},
+	{"libEGL_mesa.so", graphicsCapabilities},
+	{"libEGL.so", graphicsCapabilities},
+	{"libglapi.so", graphicsCapabilities},
+	{"libGLdispatch.so", graphicsCapabilities},
+	{"libGLESv1_CM.so", graphicsCapabilities},
+	{"libGLESv1_CM_xdxgpu.so", graphicsCapabilities},
+	{"libGLESv2.so", graphicsCapabilities},
+	{"libGLESv2_xdxgpu.so", graphicsCapabilities},
+	{"libGL.so", graphicsCapabilities},
+	{"libGL_xdxgpu.so", graphicsCapabilities},
+	{"libGLX_mesa.so", graphicsCapabilities},
+	{"libGLX.so", graphicsCapabilities},
+	{"libOpenGL.so", graphicsCapabilities},
+	{"libusc_xdxgpu.so", compilerCapabilities},
+	{"libufgen_xdxgpu.so", compilerCapabilities},
+	{"libgsl_xdxgpu.so", compilerCapabilities},
+	{"libdri_xdxgpu.so", graphicsCapabilities},
+	{"libdrm_xdxgpu.so", drmCapabilities},
+	{"libvlk_xdxgpu.so", graphicsCapabilities},
+	{"libxdxgpu_mesa_wsi.so", graphicsCapabilities},
+	{"libOpenCL.so*", []image.DriverCapability{image.DriverCapabilityCompute}},
+}
+
+// getDriverLibraries returns the patterns for the driver libraries required by the specified capabilities.
+func getDriverLibraries(capabilities image.DriverCapabilities) []string {
+	var libraries []string
+	for _, l := range driverLibraries {
+		if capabilities.Any(l.capabilities...) {
+			libraries = append(libraries, l.pattern)
+		}
+	}
+	return libraries
+}
+
 // NewDriverLibraryDiscoverer creates a discoverer for the libraries associated with the specified driver version.
 func NewDriverLibraryDiscoverer(logger logger.Interface, driver *root.Driver, xdxctCTKPath string) (discover.Discover, error) {
-	libxdxgpu_driver := []string{
-		"libxdxgpu-ml.so.*.*",
-		"libdrm.so",
-		"libva-drm.so",
-		"libva.so",
-		"libva-x11.so",
-		"libEGL_mesa.so",
-		"libEGL.so",
-		"libglapi.so",
-		"libGLdispatch.so",
-		"libGLESv1_CM.so",
-		"libGLESv1_CM_xdxgpu.so",
-		"libGLESv2.so",
-		"libGLESv2_xdxgpu.so",
-		"libGL.so",
-		"libGL_xdxgpu.so",
-		"libGLX_mesa.so",
-		"libGLX.so",
-		"libOpenGL.so",
-		"libusc_xdxgpu.so",
-		"libufgen_xdxgpu.so",
-		"libgsl_xdxgpu.so",
-		"libdri_xdxgpu.so",
-		"libdrm_xdxgpu.so",
-		"libvlk_xdxgpu.so",
-		"libxdxgpu_mesa_wsi.so",
-		"libOpenCL.so*",
-	}
+	return newDriverLibraryDiscoverer(logger, driver, xdxctCTKPath, image.NewDriverCapabilities("all"), false)
+}
 
+func newDriverLibraryDiscoverer(logger logger.Interface, driver *root.Driver, xdxctCTKPath string, capabilities image.DriverCapabilities, devSymlinks bool) (discover.Discover, error) {
+	libxdxgpu_driver := getDriverLibraries(capabilities)
+	if len(libxdxgpu_driver) == 0 {
+		return discover.None{}, nil
+	}
 	libraries := discover.NewMounts(
 		logger,
 		lookup.NewFileLocator(
@@ -109,10 +149,14 @@ func NewDriverLibraryDiscoverer(logger logger.Interface, driver *root.Driver, xd
 		libxdxgpu_driver,
 	)
 
+	// The SONAME symlinks are created before the ldcache is updated so that these are used as the
+	// paths of the libraries in the cache.
+	sonameSymlinks := discover.NewSonameSymlinksHook(logger, libraries, xdxctCTKPath, devSymlinks)
 	hooks, _ := discover.NewLDCacheUpdateHook(logger, libraries, xdxctCTKPath)
 
 	d := discover.Merge(
 		libraries,
+		sonameSymlinks,
 		hooks,
 	)
 
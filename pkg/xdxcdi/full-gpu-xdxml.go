@@ -100,10 +100,16 @@ func newFullGPUDiscoverer(logger logger.Interface, devRoot string, xdxctCTKPath
 		deviceNodes,
 	)
 
+	manifestHook := discover.CreateWriteManifestHook(
+		xdxctCTKPath,
+		"--device", getManifestDevice(logger, d, pciBusID),
+	)
+
 	dd := discover.Merge(
 		deviceNodes,
 		byPathHooks,
 		deviceFolderPermissionHooks,
+		manifestHook,
 	)
 
 	return dd, nil
@@ -189,6 +195,17 @@ func (d *byPathHookDiscoverer) deviceNodeLinks() ([]string, error) {
 	return links, nil
 }
 
+// getManifestDevice returns the description of the device for the write-manifest hook.
+// The UUID is omitted if it cannot be queried.
+func getManifestDevice(logger logger.Interface, d device.Device, pciBusID string) string {
+	uuid, ret := d.GetUUID()
+	if ret != xdxml.SUCCESS || uuid == "" {
+		logger.Warningf("Failed to get UUID for device %v: %v", pciBusID, ret)
+		return fmt.Sprintf("pci=%v", pciBusID)
+	}
+	return fmt.Sprintf("uuid=%v,pci=%v", uuid, pciBusID)
+}
+
 // getBusID provides a utility function that returns the string representation of the bus ID.
 func getBusID(p xdxml.PciInfo) string {
 	busStr := fmt.Sprintf("%02x", p.Bus)
@@ -3,6 +3,7 @@ package xdxcdi
 import (
 	"fmt"
 
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/lookup/root"
 	"github.com/XDXCT/xdxct-container-toolkit/pkg/xdxcdi/spec"
@@ -37,6 +38,9 @@ type xdxcdilib struct {
 	vendor string
 	class  string
 
+	driverCapabilities image.DriverCapabilities
+	devSymlinks        bool
+
 	driver  *root.Driver
 
 	mergedDeviceOptions []transform.MergedDeviceOption
@@ -66,6 +70,9 @@ func New(opts ...Option) (Interface, error) {
 	if l.xdxctCTKPath == "" {
 		l.xdxctCTKPath = "/usr/bin/xdxct-ctk"
 	}
+	if l.driverCapabilities == nil {
+		l.driverCapabilities = image.NewDriverCapabilities("all")
+	}
 
 	// TODO: We need to improve the construction of this driver root.
 	l.driver = root.New(l.logger, l.driverRoot, l.librarySearchPaths)
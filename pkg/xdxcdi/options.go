@@ -1,6 +1,7 @@
 package xdxcdi
 
 import (
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config/image"
 	"github.com/XDXCT/xdxct-container-toolkit/internal/logger"
 	"github.com/XDXCT/xdxct-container-toolkit/pkg/xdxcdi/transform"
 	"github.com/XDXCT/xdxct-container-toolkit/pkg/go-xdxlib/device"
@@ -58,6 +59,22 @@ func WithXdxmlLib(xdxmllib xdxml.Interface) Option {
 	}
 }
 
+// WithDriverCapabilities sets the driver capabilities used to select the driver files to inject.
+// If no capabilities are set, the files for all capabilities are injected.
+func WithDriverCapabilities(capabilities image.DriverCapabilities) Option {
+	return func(l *xdxcdilib) {
+		l.driverCapabilities = capabilities
+	}
+}
+
+// WithDevSymlinks sets whether the development (.so) symlinks are created for the driver
+// libraries in addition to the SONAME symlinks.
+func WithDevSymlinks(devSymlinks bool) Option {
+	return func(l *xdxcdilib) {
+		l.devSymlinks = devSymlinks
+	}
+}
+
 // WithMode sets the discovery mode for the library
 func WithMode(mode string) Option {
 	return func(l *xdxcdilib) {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
 	"github.com/XDXCT/xdxct-container-toolkit/pkg/xdxcdi/transform"
 	"tags.cncf.io/container-device-interface/pkg/cdi"
 	"tags.cncf.io/container-device-interface/specs-go"
@@ -21,6 +22,7 @@ type builder struct {
 	mergedDeviceOptions []transform.MergedDeviceOption
 	noSimplify          bool
 	permissions         os.FileMode
+	hookTimeouts        config.HookTimeouts
 }
 
 // newBuilder creates a new spec builder with the supplied options
@@ -91,6 +93,12 @@ func (o *builder) Build() (*spec, error) {
 		}
 	}
 
+	if len(o.hookTimeouts) > 0 {
+		if err := transform.NewHookTimeouts(o.hookTimeouts).Transform(raw); err != nil {
+			return nil, fmt.Errorf("failed to set hook timeouts: %v", err)
+		}
+	}
+
 	s := spec{
 		Spec:        raw,
 		format:      o.format,
@@ -172,3 +180,10 @@ func WithMergedDeviceOptions(opts ...transform.MergedDeviceOption) Option {
 		o.mergedDeviceOptions = opts
 	}
 }
+
+// WithHookTimeouts sets the timeouts for the xdxct-ctk hooks in the spec.
+func WithHookTimeouts(timeouts config.HookTimeouts) Option {
+	return func(o *builder) {
+		o.hookTimeouts = timeouts
+	}
+}
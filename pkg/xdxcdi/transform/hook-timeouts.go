@@ -0,0 +1,39 @@
+package transform
+
+import (
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+	"tags.cncf.io/container-device-interface/specs-go"
+)
+
+type hookTimeouts config.HookTimeouts
+
+var _ Transformer = (*hookTimeouts)(nil)
+
+// NewHookTimeouts creates a transformer that sets the timeouts of the xdxct-ctk hooks in a spec.
+// Hooks that already have a timeout are not modified.
+func NewHookTimeouts(timeouts config.HookTimeouts) Transformer {
+	return hookTimeouts(timeouts)
+}
+
+// Transform sets the timeouts of the hooks in the common and device-specific edits.
+func (t hookTimeouts) Transform(spec *specs.Spec) error {
+	if spec == nil {
+		return nil
+	}
+
+	for _, device := range spec.Devices {
+		t.transformEdits(&device.ContainerEdits)
+	}
+	t.transformEdits(&spec.ContainerEdits)
+
+	return nil
+}
+
+func (t hookTimeouts) transformEdits(edits *specs.ContainerEdits) {
+	for _, hook := range edits.Hooks {
+		if hook == nil || hook.Timeout != nil {
+			continue
+		}
+		hook.Timeout = config.HookTimeouts(t).Get(hook.Path, hook.Args)
+	}
+}
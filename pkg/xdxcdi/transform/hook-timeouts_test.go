@@ -0,0 +1,97 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tags.cncf.io/container-device-interface/specs-go"
+
+	"github.com/XDXCT/xdxct-container-toolkit/internal/config"
+)
+
+func TestHookTimeouts(t *testing.T) {
+	timeout := func(seconds int) *int {
+		return &seconds
+	}
+	timeouts := config.HookTimeouts{
+		config.DefaultHookTimeoutKey: 30,
+		"update-ldcache":             120,
+		"chmod":                      0,
+	}
+
+	testCases := []struct {
+		description string
+		spec        *specs.Spec
+		expected    *specs.Spec
+	}{
+		{
+			description: "nil spec",
+		},
+		{
+			description: "timeouts are set for common and device hooks",
+			spec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{
+						{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "update-ldcache"}},
+						{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "create-symlinks"}},
+					},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							Hooks: []*specs.Hook{
+								{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "chmod"}},
+							},
+						},
+					},
+				},
+			},
+			expected: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{
+						{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "update-ldcache"}, Timeout: timeout(120)},
+						{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "create-symlinks"}, Timeout: timeout(30)},
+					},
+				},
+				Devices: []specs.Device{
+					{
+						Name: "0",
+						ContainerEdits: specs.ContainerEdits{
+							Hooks: []*specs.Hook{
+								{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "chmod"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "existing timeouts and other hooks are not modified",
+			spec: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{
+						{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "update-ldcache"}, Timeout: timeout(5)},
+						{HookName: "createContainer", Path: "/usr/bin/other-hook", Args: []string{"other-hook", "hook", "update-ldcache"}},
+					},
+				},
+			},
+			expected: &specs.Spec{
+				ContainerEdits: specs.ContainerEdits{
+					Hooks: []*specs.Hook{
+						{HookName: "createContainer", Path: "/usr/bin/xdxct-ctk", Args: []string{"xdxct-ctk", "hook", "update-ldcache"}, Timeout: timeout(5)},
+						{HookName: "createContainer", Path: "/usr/bin/other-hook", Args: []string{"other-hook", "hook", "update-ldcache"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := NewHookTimeouts(timeouts).Transform(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tc.spec)
+		})
+	}
+}
@@ -40,6 +40,8 @@ func (d *deviceFolderPermissions) Devices() ([]discover.Device, error) {
 }
 
 // Hooks returns a set of hooks that sets the file mode to 755 of parent folders for nested device nodes.
+// The owner and group of the folders is also set to root in the container so that these are
+// accessible in user-namespaced containers.
 func (d *deviceFolderPermissions) Hooks() ([]discover.Hook, error) {
 	folders, err := d.getDeviceSubfolders()
 	if err != nil {
@@ -49,18 +51,23 @@ func (d *deviceFolderPermissions) Hooks() ([]discover.Hook, error) {
 		return nil, nil
 	}
 
-	args := []string{"--mode", "755"}
+	var pathArgs []string
 	for _, folder := range folders {
-		args = append(args, "--path", folder)
+		pathArgs = append(pathArgs, "--path", folder)
 	}
 
-	hook := discover.CreateXdxctCTKHook(
+	chmodHook := discover.CreateXdxctCTKHook(
 		d.xdxctCTKPath,
 		"chmod",
-		args...,
+		append([]string{"--mode", "755"}, pathArgs...)...,
+	)
+	chownHook := discover.CreateXdxctCTKHook(
+		d.xdxctCTKPath,
+		"chown",
+		append([]string{"--owner", "0", "--group", "0"}, pathArgs...)...,
 	)
 
-	return []discover.Hook{hook}, nil
+	return []discover.Hook{chownHook, chmodHook}, nil
 }
 
 func (d *deviceFolderPermissions) getDeviceSubfolders() ([]string, error) {